@@ -1,22 +1,71 @@
 package sqlquery
 
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/huandu/go-sqlbuilder"
+)
+
 // Supported flavors.
+//
+// MariaDBFlavor compiles like MySQLFlavor but enables the MariaDB only syntax,
+// like the "WAIT n" lock clause that MySQL doesn't support.
 const (
 	MySQLFlavor Flavor = iota + 1
 	PostgreSQLFlavor
 	SQLiteFlavor
+	MariaDBFlavor
 )
 
 // Flavor is the flag to control the format of compiled sql.
 type Flavor int
 
+func (f Flavor) builderFlavor() sqlbuilder.Flavor {
+	if f == MariaDBFlavor {
+		return sqlbuilder.MySQL
+	}
+	return sqlbuilder.Flavor(f)
+}
+
+// Supported empty in behaviors.
+const (
+	// EmptyInMatchNothing renders "1 = 0" for an empty "in" filter and ignores an empty "notin" filter,
+	// since an empty set matches no rows.
+	EmptyInMatchNothing EmptyInBehavior = iota
+	// EmptyInSkip ignores empty "in" and "notin" filters.
+	EmptyInSkip
+)
+
+// EmptyInBehavior controls how "in" and "notin" filters with an empty or whitespace-only value are handled.
+type EmptyInBehavior int
+
 // FindOptions provides configuration for FindQuery function.
 type FindOptions struct {
-	Flavor        Flavor
-	Fields        []string
-	Filters       map[string]interface{}
-	ForUpdate     bool
-	ForUpdateMode string
+	Flavor           Flavor
+	Fields           []string
+	Filters          map[string]interface{}
+	ForUpdate        bool
+	ForUpdateMode    string
+	ForShare         bool
+	ForShareMode     string
+	LockWait         int
+	EmptyIn          EmptyInBehavior
+	SoftDeleteColumn string
+	IncludeDeleted   bool
+	Schema           string
+	VirtualColumns   []string
+	AllowedColumns   []string
+	MaxInSize        int
+	SkipNil          bool
+	TableAlias       string
+	Joins            []Join
+	ValuesJoins      []ValuesJoin
+	predicates       []predicate
+	// star backs the default "*" Fields, so the options are created with a single allocation for both.
+	star [1]string
 }
 
 // WithFields is a helper function to construct functional options that sets Fields field.
@@ -29,37 +78,579 @@ func (f *FindOptions) WithFields(fields []string) *FindOptions {
 // WithFilter is a helper function to construct functional options that sets Filters field.
 func (f *FindOptions) WithFilter(field string, value interface{}) *FindOptions {
 	copy := *f
+	copy.Filters = copyValues(f.Filters)
 	copy.Filters[field] = value
 	return &copy
 }
 
+// WithFilters is a helper function to construct functional options that merges filters into Filters field,
+// the existing filters with the same keys are replaced.
+func (f *FindOptions) WithFilters(filters map[string]interface{}) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	for key, value := range filters {
+		copy.Filters[key] = value
+	}
+	return &copy
+}
+
+// WithRangeFilter is a helper function to construct functional options that sets a range on Filters field.
+// The bounds use ">=" and "<=" when inclusive and ">" and "<" otherwise.
+func (f *FindOptions) WithRangeFilter(field string, low, high interface{}, lowInclusive, highInclusive bool) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	setRangeFilter(copy.Filters, field, low, high, lowInclusive, highInclusive)
+	return &copy
+}
+
 // WithForUpdate is a helper function to construct functional options that sets ForUpdate and ForUpdateMode fields.
 func (f *FindOptions) WithForUpdate(mode string) *FindOptions {
 	copy := *f
 	copy.ForUpdate = true
 	copy.ForUpdateMode = mode
+	copy.ForShare = false
+	copy.ForShareMode = ""
+	return &copy
+}
+
+// WithForShare is a helper function to construct functional options that sets ForShare and ForShareMode fields.
+// FOR SHARE and FOR UPDATE are mutually exclusive, the last one set wins. MySQL renders LOCK IN SHARE MODE,
+// or FOR SHARE when mode is set, and MariaDB always renders LOCK IN SHARE MODE.
+func (f *FindOptions) WithForShare(mode string) *FindOptions {
+	copy := *f
+	copy.ForShare = true
+	copy.ForShareMode = mode
+	copy.ForUpdate = false
+	copy.ForUpdateMode = ""
+	return &copy
+}
+
+// WithLockWait is a helper function to construct functional options that sets LockWait field.
+// The "WAIT n" clause is only rendered on MariaDBFlavor, after FOR UPDATE or LOCK IN SHARE MODE,
+// MySQL uses the innodb_lock_wait_timeout session variable instead.
+func (f *FindOptions) WithLockWait(seconds int) *FindOptions {
+	copy := *f
+	copy.LockWait = seconds
+	return &copy
+}
+
+// WithEmptyIn is a helper function to construct functional options that sets EmptyIn field.
+func (f *FindOptions) WithEmptyIn(behavior EmptyInBehavior) *FindOptions {
+	copy := *f
+	copy.EmptyIn = behavior
+	return &copy
+}
+
+// WithSoftDelete is a helper function to construct functional options that sets SoftDeleteColumn field.
+// Rows are filtered with "column IS NULL" unless IncludeDeleted is set.
+func (f *FindOptions) WithSoftDelete(column string) *FindOptions {
+	copy := *f
+	copy.SoftDeleteColumn = column
+	return &copy
+}
+
+// WithIncludeDeleted is a helper function to construct functional options that sets IncludeDeleted field.
+func (f *FindOptions) WithIncludeDeleted() *FindOptions {
+	copy := *f
+	copy.IncludeDeleted = true
+	return &copy
+}
+
+// WithBlankFilter is a helper function to construct functional options that matches NULL or empty string values.
+// It sets the "field.blank" filter.
+func (f *FindOptions) WithBlankFilter(field string) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".blank"] = true
+	return &copy
+}
+
+// WithSchema is a helper function to construct functional options that sets Schema field.
+// The schema and the table name are quoted for the flavor.
+func (f *FindOptions) WithSchema(schema string) *FindOptions {
+	copy := *f
+	copy.Schema = schema
+	return &copy
+}
+
+// WithStartsWithAny is a helper function to construct functional options that matches any of the prefixes.
+// It sets the "field.startswithany" filter, the prefixes are escaped with EscapeLike.
+func (f *FindOptions) WithStartsWithAny(field string, prefixes ...string) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".startswithany"] = prefixes
+	return &copy
+}
+
+// WithCoveringColumns is a helper function to construct functional options that sets Fields field to exactly the columns.
+// Use the columns of an index, without "*" or expressions, so the projection stays covering and the
+// database can choose an index-only scan.
+func (f *FindOptions) WithCoveringColumns(columns ...string) *FindOptions {
+	copy := *f
+	copy.Fields = columns
+	return &copy
+}
+
+// WithFloatApproxFilter is a helper function to construct functional options that matches floats approximately.
+// It sets the "column.approx" filter.
+func (f *FindOptions) WithFloatApproxFilter(column string, value, epsilon float64) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[column+".approx"] = Approx{Value: value, Epsilon: epsilon}
+	return &copy
+}
+
+// WithField is a helper function to construct functional options that appends "expr AS alias" to Fields field.
+// The alias is quoted for the flavor.
+func (f *FindOptions) WithField(expr, alias string) *FindOptions {
+	copy := *f
+	field := sqlbuilder.Escape(expr) + " AS " + f.Flavor.builderFlavor().Quote(alias)
+	copy.Fields = append(append(make([]string, 0, len(f.Fields)+1), f.Fields...), field)
+	return &copy
+}
+
+// WithTypedIn is a helper function to construct functional options that sets a "field.in" filter
+// converting the comma separated values to goType, see TypedIn.
+func (f *FindOptions) WithTypedIn(field string, csv string, goType string) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".in"] = TypedIn{Values: csv, Type: goType}
+	return &copy
+}
+
+// WithLikeAnyArray is a helper function to construct functional options that matches any of the LIKE patterns.
+// It sets the "field.likeany" filter, PostgreSQL uses LIKE ANY(ARRAY[...]) and the other flavors OR-ed LIKEs.
+func (f *FindOptions) WithLikeAnyArray(field string, patterns []string) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".likeany"] = patterns
+	return &copy
+}
+
+// WithVirtualColumns is a helper function to construct functional options that sets VirtualColumns field.
+// It is only a hint used by NormalizedFilters to flag the filters on generated columns, the SQL is not changed.
+func (f *FindOptions) WithVirtualColumns(columns ...string) *FindOptions {
+	copy := *f
+	copy.VirtualColumns = columns
+	return &copy
+}
+
+// NormalizedFilters returns the filters split into field and operator, sorted by key.
+func (f *FindOptions) NormalizedFilters() []NormalizedFilter {
+	return normalizeFilters(f.Filters, f.VirtualColumns)
+}
+
+// WithUUIDFilter is a helper function to construct functional options that matches a UUID column.
+// It sets the "field.uuid" filter, a malformed value is an ErrInvalidValue error on the E functions.
+func (f *FindOptions) WithUUIDFilter(field string, value string) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".uuid"] = value
+	return &copy
+}
+
+// WithNotGroup is a helper function to construct functional options that excludes the rows matching all filters,
+// like NOT (a = 1 AND b = 2). The filters use the same keys and operators of Filters field.
+func (f *FindOptions) WithNotGroup(filters map[string]interface{}) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, notGroupPredicate(filters))
+	return &copy
+}
+
+// WithJSONFilter is a helper function to construct functional options that compares the dot separated path
+// of the JSON column with value, like data#>>$1::text[] with the {status} path bound on PostgreSQL and JSON_EXTRACT(data, ?) with the "$.status" path bound on MySQL.
+func (f *FindOptions) WithJSONFilter(column, path string, value interface{}) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, jsonPredicate(column, path, value))
+	return &copy
+}
+
+// WithInSubquery is a helper function to construct functional options that matches the column with the rows
+// selected by sub from subTable, like team_id IN (SELECT id FROM teams WHERE active = $1).
+// The sub Fields should select a single column and the args are numbered with the outer query args.
+func (f *FindOptions) WithInSubquery(column string, sub *FindOptions, subTable string) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, inSubqueryPredicate(column, sub, subTable))
+	return &copy
+}
+
+// WithNumericTextFilter is a helper function to construct functional options that compares a text column
+// as a number, like CAST(column AS INTEGER) > $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte",
+// the castType must be a numeric type supported by the flavor CAST, like "SIGNED" on MySQL and "INTEGER" on PostgreSQL.
+func (f *FindOptions) WithNumericTextFilter(column, op string, value interface{}, castType string) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, numericTextPredicate(column, op, value, castType))
+	return &copy
+}
+
+// WithTimeInInterval is a helper function to construct functional options that matches the rows whose [startCol, endCol) interval contains value.
+func (f *FindOptions) WithTimeInInterval(value interface{}, startCol, endCol string) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, timeInIntervalPredicate(value, startCol, endCol))
+	return &copy
+}
+
+// WithAllowedColumns is a helper function to construct functional options that sets AllowedColumns field.
+// When it is set the filters on other columns are skipped, use the E functions to get an ErrInvalidColumn error instead.
+// The operator suffix is ignored, so the "id.gte" filter checks "id".
+func (f *FindOptions) WithAllowedColumns(columns ...string) *FindOptions {
+	copy := *f
+	copy.AllowedColumns = columns
+	return &copy
+}
+
+// WithCtidFilter is a helper function to construct functional options that matches the PostgreSQL row with
+// the physical location ctid, like "(0,1)". It sets the "ctid.tid" filter, a malformed ctid is an ErrInvalidValue error
+// and the other flavors an ErrUnsupportedOperator error on the E functions.
+func (f *FindOptions) WithCtidFilter(ctid string) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters["ctid.tid"] = ctid
+	return &copy
+}
+
+// WithDateIn is a helper function to construct functional options that sets a "field.in" filter
+// parsing the comma separated dates with layout, DateLayout is used when layout is empty.
+func (f *FindOptions) WithDateIn(field string, csv string, layout string) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".in"] = TypedIn{Values: csv, Type: "date", Layout: layout}
+	return &copy
+}
+
+// WithTableAlias is a helper function to construct functional options that sets TableAlias field.
+// The table is rendered as "table AS alias" and the bare columns of Fields, filters, orders and the
+// soft delete column are qualified with alias. A filter key like "teams.id" or "teams.id.in" is kept
+// as a qualified column when the alias is set.
+func (f *FindOptions) WithTableAlias(alias string) *FindOptions {
+	copy := *f
+	copy.TableAlias = alias
+	return &copy
+}
+
+// WithValuesJoin is a helper function to construct functional options that appends an INNER JOIN of the rows
+// to ValuesJoins field, like JOIN (VALUES ($1, $2), ($3, $4)) AS v (id, weight) ON players.id = v.id.
+// The rows are joined on the first column and their args come before the filters args. MySQL uses the
+// VALUES ROW(...) form, SQLite and MariaDB use a UNION ALL of SELECT.
+func (f *FindOptions) WithValuesJoin(alias string, columns []string, rows [][]interface{}) *FindOptions {
+	copy := *f
+	join := ValuesJoin{Alias: alias, Columns: columns, Rows: rows}
+	copy.ValuesJoins = append(append(make([]ValuesJoin, 0, len(f.ValuesJoins)+1), f.ValuesJoins...), join)
+	return &copy
+}
+
+// WithQualifiedStar is a helper function to construct functional options that selects all the columns of table,
+// like SELECT players.*, teams.name FROM players JOIN teams, to avoid ambiguous columns on joins. The default "*"
+// field is replaced and the qualified stars are kept before the other fields, in the order they were added.
+func (f *FindOptions) WithQualifiedStar(table string) *FindOptions {
+	copy := *f
+	fields := f.Fields
+	if len(fields) == 1 && fields[0] == "*" {
+		fields = nil
+	}
+	i := 0
+	for i < len(fields) && strings.HasSuffix(fields[i], ".*") {
+		i++
+	}
+	copy.Fields = make([]string, 0, len(fields)+1)
+	copy.Fields = append(copy.Fields, fields[:i]...)
+	copy.Fields = append(copy.Fields, table+".*")
+	copy.Fields = append(copy.Fields, fields[i:]...)
+	return &copy
+}
+
+// WithJoin is a helper function to construct functional options that appends an INNER JOIN of table on onExpr to Joins field.
+func (f *FindOptions) WithJoin(table, onExpr string) *FindOptions {
+	copy := *f
+	copy.Joins = append(append(make([]Join, 0, len(f.Joins)+1), f.Joins...), Join{Table: table, On: onExpr})
+	return &copy
+}
+
+// WithRoundedFilter is a helper function to construct functional options that compares the column rounded
+// to decimals with value, like ROUND(amount, 2) = $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte",
+// PostgreSQL only rounds numeric columns to decimals.
+func (f *FindOptions) WithRoundedFilter(column string, decimals int, op string, value interface{}) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, roundedPredicate(column, decimals, op, value))
+	return &copy
+}
+
+// WithSearch is a helper function to construct functional options that matches the rows with any of the columns
+// containing value, like (name LIKE $1 OR nickname LIKE $2). The value is escaped with EscapeLike and bound once
+// per column, an empty value is ignored.
+func (f *FindOptions) WithSearch(value string, columns ...string) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, searchPredicate(value, columns))
+	return &copy
+}
+
+// WithColumnComparison is a helper function to construct functional options that compares two columns,
+// like start_date < end_date, without binding right as an arg. The op is one of "=", "<>", "!=", "<", "<=", ">" and ">=".
+func (f *FindOptions) WithColumnComparison(left, op, right string) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, columnComparisonPredicate(left, op, right))
+	return &copy
+}
+
+// WithNonASCIIFilter is a helper function to construct functional options that matches the rows with any
+// non-ASCII character in the column, like name !~ '^[[:ascii:]]*$' on PostgreSQL and
+// name <> CONVERT(name USING ascii) on MySQL and MariaDB. SQLite is not supported.
+func (f *FindOptions) WithNonASCIIFilter(column string) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, nonASCIIPredicate(column))
+	return &copy
+}
+
+// WithDateEqualsFilter is a helper function to construct functional options that matches the rows whose
+// timestamp column is in the day of date, ignoring the time, like created_at::date = $1 on PostgreSQL
+// and DATE(created_at) = ? on MySQL, MariaDB and SQLite. The date is bound as "2006-01-02".
+func (f *FindOptions) WithDateEqualsFilter(column string, date time.Time) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, dateEqualsPredicate(column, date))
+	return &copy
+}
+
+// WithArrayIndexFilter is a helper function to construct functional options that compares the element of the
+// PostgreSQL array column at index with value, like tags[1] = $1. The op is one of "" (equal), "not", "gt", "gte",
+// "lt" and "lte", the index starts at 1 like the PostgreSQL arrays and the other flavors return ErrUnsupportedFlavor.
+func (f *FindOptions) WithArrayIndexFilter(column string, index int, op string, value interface{}) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, arrayIndexPredicate(column, index, op, value))
+	return &copy
+}
+
+// WithMaxInSize is a helper function to construct functional options that sets MaxInSize field.
+// The "in" and "notin" filters with more than n values are skipped, use the E functions to get
+// an ErrInvalidValue error instead. Zero means no limit.
+func (f *FindOptions) WithMaxInSize(n int) *FindOptions {
+	copy := *f
+	copy.MaxInSize = n
+	return &copy
+}
+
+// WithExists is a helper function to construct functional options that matches the rows with a related subTable row,
+// like EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND minute > $1). The correlationExpr
+// relates subTable with the outer table, the bare subFilters columns are resolved against subTable first
+// and the args are numbered with the outer query args.
+func (f *FindOptions) WithExists(subTable, correlationExpr string, subFilters map[string]interface{}) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, existsPredicate(subTable, correlationExpr, copyValues(subFilters), false))
+	return &copy
+}
+
+// WithNotExists is a helper function to construct functional options that matches the rows without a related
+// subTable row, like NOT EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND minute > $1).
+// It takes the same arguments as WithExists.
+func (f *FindOptions) WithNotExists(subTable, correlationExpr string, subFilters map[string]interface{}) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, existsPredicate(subTable, correlationExpr, copyValues(subFilters), true))
+	return &copy
+}
+
+// WithNullSafeEqual is a helper function to construct functional options that matches the field equal to value
+// where NULL is equal to NULL, like name <=> ? on MySQL and MariaDB, name IS NOT DISTINCT FROM $1 on PostgreSQL
+// and name IS ? on SQLite. It sets the "field.notdistinctfrom" filter.
+func (f *FindOptions) WithNullSafeEqual(field string, value interface{}) *FindOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".notdistinctfrom"] = value
+	return &copy
+}
+
+// WithSkipNil is a helper function to construct functional options that sets SkipNil field.
+// The filters with a nil value or a nil pointer are skipped instead of matching NULL with IS NULL,
+// so a map of optional parameters can be used as the filters.
+func (f *FindOptions) WithSkipNil() *FindOptions {
+	copy := *f
+	copy.SkipNil = true
+	return &copy
+}
+
+// WithGreatestFilter is a helper function to construct functional options that compares the greatest of the columns
+// with value, like GREATEST(home_goals, away_goals) > $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte".
+// SQLite uses the multi-argument MAX function. PostgreSQL ignores the NULL columns, MySQL, MariaDB and SQLite
+// return NULL when any column is NULL.
+func (f *FindOptions) WithGreatestFilter(columns []string, op string, value interface{}) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, extremePredicate(columns, false, op, value))
+	return &copy
+}
+
+// WithLeastFilter is a helper function to construct functional options that compares the least of the columns
+// with value, like LEAST(home_goals, away_goals) > $1. It works like WithGreatestFilter and SQLite uses
+// the multi-argument MIN function.
+func (f *FindOptions) WithLeastFilter(columns []string, op string, value interface{}) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, extremePredicate(columns, true, op, value))
+	return &copy
+}
+
+// WithRangeListFilter is a helper function to construct functional options that matches the field between
+// the inclusive bounds of any of the ranges, like (age BETWEEN $1 AND $2 OR age BETWEEN $3 AND $4).
+// An empty ranges list follows EmptyIn like an empty "in" filter.
+func (f *FindOptions) WithRangeListFilter(field string, ranges [][2]interface{}) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, rangeListPredicate(field, ranges))
+	return &copy
+}
+
+// Optimize returns a copy of the options with the redundant Filters collapsed, like FindAllOptions.Optimize.
+func (f *FindOptions) Optimize() *FindOptions {
+	copy := *f
+	copy.Filters = optimizeFilters(f.Filters)
+	return &copy
+}
+
+// WithTextLikeFilter is a helper function to construct functional options that matches the rows whose column
+// cast to text contains pattern, like id::text LIKE '%42%' on PostgreSQL, CAST(id AS CHAR) on MySQL and MariaDB
+// and CAST(id AS TEXT) on SQLite, to search numeric or uuid columns. The LIKE metacharacters of pattern are
+// escaped and an empty pattern is ignored.
+func (f *FindOptions) WithTextLikeFilter(column, pattern string) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, textLikePredicate(column, pattern))
+	return &copy
+}
+
+// WithEpochFilter is a helper function to construct functional options that compares the column storing unix epoch
+// seconds with t, like created_at > $1 with t.Unix() bound. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte".
+func (f *FindOptions) WithEpochFilter(column, op string, t time.Time) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, epochPredicate(column, op, t))
+	return &copy
+}
+
+// WithCondition is a helper function to construct functional options that adds the boolean filter tree condition,
+// like (a = $1 AND (b = $2 OR c = $3)) for AndCondition(FilterCondition("a", 1), OrCondition(FilterCondition("b", 2),
+// FilterCondition("c", 3))). The leaves use the same keys and operators of Filters field.
+func (f *FindOptions) WithCondition(condition Condition) *FindOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, conditionPredicate(condition))
 	return &copy
 }
 
+func (f *FindOptions) lockConfig() lockConfig {
+	if f.ForShare {
+		return lockConfig{forShare: true, mode: f.ForShareMode, wait: f.LockWait}
+	}
+	return lockConfig{forUpdate: f.ForUpdate, mode: f.ForUpdateMode, wait: f.LockWait}
+}
+
+func (f *FindOptions) filterConfig() filterConfig {
+	return filterConfig{flavor: f.Flavor, emptyIn: f.EmptyIn, qualifier: f.TableAlias, allowedColumns: f.AllowedColumns, maxInSize: f.MaxInSize, skipNil: f.SkipNil}
+}
+
 // NewFindOptions returns a FindOptions.
 func NewFindOptions(flavor Flavor) *FindOptions {
-	return &FindOptions{
-		Fields:  []string{"*"},
+	options := &FindOptions{
 		Flavor:  flavor,
 		Filters: make(map[string]interface{}),
 	}
+	options.star[0] = "*"
+	options.Fields = options.star[:]
+	return options
+}
+
+// Direction is the sort direction of an OrderBy.
+type Direction int
+
+// Supported sort directions.
+const (
+	Asc Direction = iota
+	Desc
+)
+
+// String returns the sql keyword of the direction.
+func (d Direction) String() string {
+	switch d {
+	case Asc:
+		return "ASC"
+	case Desc:
+		return "DESC"
+	}
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// OrderBy is a column of the ORDER BY clause, the column is validated before rendering
+// so it is safe to use with user input.
+type OrderBy struct {
+	Column    string
+	Direction Direction
+}
+
+// expr returns the ORDER BY expression or an error if the column or the direction are invalid.
+func (o OrderBy) expr() (string, error) {
+	if !isColumnName(o.Column) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidColumn, o.Column)
+	}
+	if o.Direction != Asc && o.Direction != Desc {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDirection, o.Direction)
+	}
+	return o.Column + " " + o.Direction.String(), nil
+}
+
+// Join is an INNER JOIN of Table on the On expression.
+type Join struct {
+	Table string
+	On    string
+}
+
+// ValuesJoin is an INNER JOIN of an inline VALUES list aliased as Alias with the Columns names,
+// joined on the first column. A column may have a PostgreSQL type, like "id::bigint", that is cast
+// on the first row because PostgreSQL types the VALUES parameters as text, the other flavors ignore it.
+type ValuesJoin struct {
+	Alias   string
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// RecursiveCTE describes a "WITH RECURSIVE name AS (BaseQuery UNION ALL RecursiveQuery)" common table expression.
+// The queries use "$?" as the placeholder for Args, which are numbered before the filters args.
+type RecursiveCTE struct {
+	Name           string
+	BaseQuery      string
+	RecursiveQuery string
+	Args           []interface{}
 }
 
 // FindAllOptions provides configuration for FindAllQuery function.
 type FindAllOptions struct {
-	Flavor        Flavor
-	Fields        []string
-	Filters       map[string]interface{}
-	Limit         int
-	Offset        int
-	OrderBy       string
-	ForUpdate     bool
-	ForUpdateMode string
+	Flavor           Flavor
+	Fields           []string
+	Filters          map[string]interface{}
+	Limit            int
+	Offset           int
+	Fetch            bool
+	OrderBy          string
+	ForUpdate        bool
+	ForUpdateMode    string
+	ForShare         bool
+	ForShareMode     string
+	LockWait         int
+	EmptyIn          EmptyInBehavior
+	SoftDeleteColumn string
+	IncludeDeleted   bool
+	Schema           string
+	CursorColumn     string
+	CursorValue      interface{}
+	CursorDirection  string
+	CursorToken      string
+	RecursiveCTE     *RecursiveCTE
+	TieBreaker       string
+	Orders           []OrderBy
+	RandomSeedColumn string
+	RandomSeed       string
+	VirtualColumns   []string
+	AllowedColumns   []string
+	MaxInSize        int
+	SkipNil          bool
+	TableAlias       string
+	Joins            []Join
+	ValuesJoins      []ValuesJoin
+	SelectPrefix     []string
+	FieldOrder       []string
+	predicates       []predicate
+	// star backs the default "*" Fields like FindOptions.star.
+	star [1]string
 }
 
 // WithFields is a helper function to construct functional options that sets Fields field.
@@ -72,10 +663,31 @@ func (f *FindAllOptions) WithFields(fields []string) *FindAllOptions {
 // WithFilter is a helper function to construct functional options that sets Filters field.
 func (f *FindAllOptions) WithFilter(field string, value interface{}) *FindAllOptions {
 	copy := *f
+	copy.Filters = copyValues(f.Filters)
 	copy.Filters[field] = value
 	return &copy
 }
 
+// WithFilters is a helper function to construct functional options that merges filters into Filters field,
+// the existing filters with the same keys are replaced.
+func (f *FindAllOptions) WithFilters(filters map[string]interface{}) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	for key, value := range filters {
+		copy.Filters[key] = value
+	}
+	return &copy
+}
+
+// WithRangeFilter is a helper function to construct functional options that sets a range on Filters field.
+// The bounds use ">=" and "<=" when inclusive and ">" and "<" otherwise.
+func (f *FindAllOptions) WithRangeFilter(field string, low, high interface{}, lowInclusive, highInclusive bool) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	setRangeFilter(copy.Filters, field, low, high, lowInclusive, highInclusive)
+	return &copy
+}
+
 // WithLimit is a helper function to construct functional options that sets Limit field.
 func (f *FindAllOptions) WithLimit(limit int) *FindAllOptions {
 	copy := *f
@@ -102,40 +714,1232 @@ func (f *FindAllOptions) WithForUpdate(mode string) *FindAllOptions {
 	copy := *f
 	copy.ForUpdate = true
 	copy.ForUpdateMode = mode
+	copy.ForShare = false
+	copy.ForShareMode = ""
 	return &copy
 }
 
-// NewFindAllOptions returns a FindAllOptions.
-func NewFindAllOptions(flavor Flavor) *FindAllOptions {
-	return &FindAllOptions{
-		Fields:  []string{"*"},
-		Flavor:  flavor,
-		Filters: make(map[string]interface{}),
-	}
+// WithForShare is a helper function to construct functional options that sets ForShare and ForShareMode fields.
+// FOR SHARE and FOR UPDATE are mutually exclusive, the last one set wins. MySQL renders LOCK IN SHARE MODE,
+// or FOR SHARE when mode is set, and MariaDB always renders LOCK IN SHARE MODE.
+func (f *FindAllOptions) WithForShare(mode string) *FindAllOptions {
+	copy := *f
+	copy.ForShare = true
+	copy.ForShareMode = mode
+	copy.ForUpdate = false
+	copy.ForUpdateMode = ""
+	return &copy
 }
 
-// UpdateOptions provides configuration for UpdateWithOptionsQuery function.
-type UpdateOptions struct {
-	Flavor      Flavor
-	Assignments map[string]interface{}
-	Filters     map[string]interface{}
+// WithLockWait is a helper function to construct functional options that sets LockWait field.
+// The "WAIT n" clause is only rendered on MariaDBFlavor, after FOR UPDATE or LOCK IN SHARE MODE,
+// MySQL uses the innodb_lock_wait_timeout session variable instead.
+func (f *FindAllOptions) WithLockWait(seconds int) *FindAllOptions {
+	copy := *f
+	copy.LockWait = seconds
+	return &copy
 }
 
-// WithAssignment is a helper function to construct functional options that sets assignments.
-func (u *UpdateOptions) WithAssignment(field string, value interface{}) *UpdateOptions {
-	copy := *u
-	copy.Assignments[field] = value
+// WithEmptyIn is a helper function to construct functional options that sets EmptyIn field.
+func (f *FindAllOptions) WithEmptyIn(behavior EmptyInBehavior) *FindAllOptions {
+	copy := *f
+	copy.EmptyIn = behavior
 	return &copy
 }
 
-// WithFilter is a helper function to construct functional options that sets Filters field.
-func (u *UpdateOptions) WithFilter(field string, value interface{}) *UpdateOptions {
-	copy := *u
-	copy.Filters[field] = value
+// WithSoftDelete is a helper function to construct functional options that sets SoftDeleteColumn field.
+// Rows are filtered with "column IS NULL" unless IncludeDeleted is set.
+func (f *FindAllOptions) WithSoftDelete(column string) *FindAllOptions {
+	copy := *f
+	copy.SoftDeleteColumn = column
 	return &copy
 }
 
-// NewUpdateOptions returns a UpdateOptions.
+// WithIncludeDeleted is a helper function to construct functional options that sets IncludeDeleted field.
+func (f *FindAllOptions) WithIncludeDeleted() *FindAllOptions {
+	copy := *f
+	copy.IncludeDeleted = true
+	return &copy
+}
+
+// WithBlankFilter is a helper function to construct functional options that matches NULL or empty string values.
+// It sets the "field.blank" filter.
+func (f *FindAllOptions) WithBlankFilter(field string) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".blank"] = true
+	return &copy
+}
+
+// WithSchema is a helper function to construct functional options that sets Schema field.
+// The schema and the table name are quoted for the flavor.
+func (f *FindAllOptions) WithSchema(schema string) *FindAllOptions {
+	copy := *f
+	copy.Schema = schema
+	return &copy
+}
+
+// WithStartsWithAny is a helper function to construct functional options that matches any of the prefixes.
+// It sets the "field.startswithany" filter, the prefixes are escaped with EscapeLike.
+func (f *FindAllOptions) WithStartsWithAny(field string, prefixes ...string) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".startswithany"] = prefixes
+	return &copy
+}
+
+// WithCoveringColumns is a helper function to construct functional options that sets Fields field to exactly the columns.
+// Use the columns of an index, without "*" or expressions, so the projection stays covering and the
+// database can choose an index-only scan.
+func (f *FindAllOptions) WithCoveringColumns(columns ...string) *FindAllOptions {
+	copy := *f
+	copy.Fields = columns
+	return &copy
+}
+
+// WithFloatApproxFilter is a helper function to construct functional options that matches floats approximately.
+// It sets the "column.approx" filter.
+func (f *FindAllOptions) WithFloatApproxFilter(column string, value, epsilon float64) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[column+".approx"] = Approx{Value: value, Epsilon: epsilon}
+	return &copy
+}
+
+// WithCursor is a helper function to construct functional options that sets CursorColumn, CursorValue and CursorDirection fields.
+// The direction is "asc" or "desc", the cursor is used by FindAllCursorQuery.
+func (f *FindAllOptions) WithCursor(column string, lastValue interface{}, direction string) *FindAllOptions {
+	copy := *f
+	copy.CursorColumn = column
+	copy.CursorValue = lastValue
+	copy.CursorDirection = direction
+	return &copy
+}
+
+// WithField is a helper function to construct functional options that appends "expr AS alias" to Fields field.
+// The alias is quoted for the flavor.
+func (f *FindAllOptions) WithField(expr, alias string) *FindAllOptions {
+	copy := *f
+	field := sqlbuilder.Escape(expr) + " AS " + f.Flavor.builderFlavor().Quote(alias)
+	copy.Fields = append(append(make([]string, 0, len(f.Fields)+1), f.Fields...), field)
+	return &copy
+}
+
+// WithFieldOrder is a helper function to construct functional options that sets FieldOrder field, the order of the
+// selected columns regardless of how Fields was built, for positional scanning. The fields in order are moved to the
+// front of the select list and matched by their text or the alias of a WithField field, the others keep their order.
+// A field of order that isn't in Fields is an ErrInvalidColumn error on the E functions.
+func (f *FindAllOptions) WithFieldOrder(order []string) *FindAllOptions {
+	copy := *f
+	copy.FieldOrder = order
+	return &copy
+}
+
+// WithWindowField is a helper function to construct functional options that appends the window function expr to
+// Fields field, like ROW_NUMBER() OVER (PARTITION BY team_id ORDER BY score DESC) AS "rank". The expr is rendered
+// as is, without args, and the alias is quoted for the flavor like WithField.
+func (f *FindAllOptions) WithWindowField(expr, alias string) *FindAllOptions {
+	return f.WithField(expr, alias)
+}
+
+// WithCursorToken is a helper function to construct functional options that sets CursorToken field.
+// The token is created by EncodeCursor with the values of the last row of the previous page and selects
+// the rows after it in the order of Orders followed by TieBreaker, like (id > $1) on a single column
+// or (score < $1 OR (score = $2 AND id > $3)) on "score DESC" with the "id" tie breaker.
+// Every ordered column must be in the token, use the E functions to get an error for a malformed token.
+func (f *FindAllOptions) WithCursorToken(token string) *FindAllOptions {
+	copy := *f
+	copy.CursorToken = token
+	return &copy
+}
+
+// WithRecursiveCTE is a helper function to construct functional options that sets RecursiveCTE field.
+// The queries use "$?" as the placeholder for args, select from the cte using its name as the table name.
+func (f *FindAllOptions) WithRecursiveCTE(name, baseQuery, recursiveQuery string, args ...interface{}) *FindAllOptions {
+	copy := *f
+	copy.RecursiveCTE = &RecursiveCTE{Name: name, BaseQuery: baseQuery, RecursiveQuery: recursiveQuery, Args: args}
+	return &copy
+}
+
+// WithTypedIn is a helper function to construct functional options that sets a "field.in" filter
+// converting the comma separated values to goType, see TypedIn.
+func (f *FindAllOptions) WithTypedIn(field string, csv string, goType string) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".in"] = TypedIn{Values: csv, Type: goType}
+	return &copy
+}
+
+// WithLikeAnyArray is a helper function to construct functional options that matches any of the LIKE patterns.
+// It sets the "field.likeany" filter, PostgreSQL uses LIKE ANY(ARRAY[...]) and the other flavors OR-ed LIKEs.
+func (f *FindAllOptions) WithLikeAnyArray(field string, patterns []string) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".likeany"] = patterns
+	return &copy
+}
+
+// WithOrder is a helper function to construct functional options that appends an OrderBy to Orders field.
+// The column must be a plain column name, like "name" or "players.name", the Orders are rendered after OrderBy field.
+func (f *FindAllOptions) WithOrder(column string, dir Direction) *FindAllOptions {
+	copy := *f
+	copy.Orders = append(append(make([]OrderBy, 0, len(f.Orders)+1), f.Orders...), OrderBy{Column: column, Direction: dir})
+	return &copy
+}
+
+// WithFetch is a helper function to construct functional options that sets Fetch field.
+// PostgreSQLFlavor and MariaDBFlavor render the ANSI "OFFSET n ROWS FETCH NEXT m ROWS ONLY" clause
+// instead of LIMIT and OFFSET, the other flavors ignore it.
+func (f *FindAllOptions) WithFetch() *FindAllOptions {
+	copy := *f
+	copy.Fetch = true
+	return &copy
+}
+
+// WithSelectPrefix is a helper function to construct functional options that sets SelectPrefix field.
+// The tokens are rendered right after SELECT, like SELECT SQL_CALC_FOUND_ROWS id, name, and must be one of
+// the select modifiers DISTINCT, ALL, DISTINCTROW, HIGH_PRIORITY, STRAIGHT_JOIN, SQL_SMALL_RESULT,
+// SQL_BIG_RESULT, SQL_BUFFER_RESULT, SQL_NO_CACHE and SQL_CALC_FOUND_ROWS, other tokens are an ErrInvalidValue error.
+func (f *FindAllOptions) WithSelectPrefix(tokens ...string) *FindAllOptions {
+	copy := *f
+	copy.SelectPrefix = tokens
+	return &copy
+}
+
+// WithSeededRandomOrder is a helper function to construct functional options that sets RandomSeedColumn
+// and RandomSeed fields. The rows are ordered by the hash of seedColumn and the bound seed, like
+// ORDER BY md5(id::text || $1) on PostgreSQL and ORDER BY MD5(CONCAT(id, ?)) on MySQL and MariaDB,
+// so the order looks random but is the same for the same seed. It comes before the other orders,
+// SQLite has no hash function and returns ErrUnsupportedFlavor.
+func (f *FindAllOptions) WithSeededRandomOrder(seedColumn string, seed string) *FindAllOptions {
+	copy := *f
+	copy.RandomSeedColumn = seedColumn
+	copy.RandomSeed = seed
+	return &copy
+}
+
+// WithTieBreaker is a helper function to construct functional options that sets TieBreaker field.
+// The column is appended to OrderBy, or used as OrderBy if it is empty, unless it is already ordered by,
+// use a unique column like the primary key to get a stable pagination.
+func (f *FindAllOptions) WithTieBreaker(column string) *FindAllOptions {
+	copy := *f
+	copy.TieBreaker = column
+	return &copy
+}
+
+// WithVirtualColumns is a helper function to construct functional options that sets VirtualColumns field.
+// It is only a hint used by NormalizedFilters to flag the filters on generated columns, the SQL is not changed.
+func (f *FindAllOptions) WithVirtualColumns(columns ...string) *FindAllOptions {
+	copy := *f
+	copy.VirtualColumns = columns
+	return &copy
+}
+
+// NormalizedFilters returns the filters split into field and operator, sorted by key.
+func (f *FindAllOptions) NormalizedFilters() []NormalizedFilter {
+	return normalizeFilters(f.Filters, f.VirtualColumns)
+}
+
+// WithUUIDFilter is a helper function to construct functional options that matches a UUID column.
+// It sets the "field.uuid" filter, a malformed value is an ErrInvalidValue error on the E functions.
+func (f *FindAllOptions) WithUUIDFilter(field string, value string) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".uuid"] = value
+	return &copy
+}
+
+// WithNotGroup is a helper function to construct functional options that excludes the rows matching all filters,
+// like NOT (a = 1 AND b = 2). The filters use the same keys and operators of Filters field.
+func (f *FindAllOptions) WithNotGroup(filters map[string]interface{}) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, notGroupPredicate(filters))
+	return &copy
+}
+
+// WithJSONFilter is a helper function to construct functional options that compares the dot separated path
+// of the JSON column with value, like data#>>$1::text[] with the {status} path bound on PostgreSQL and JSON_EXTRACT(data, ?) with the "$.status" path bound on MySQL.
+func (f *FindAllOptions) WithJSONFilter(column, path string, value interface{}) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, jsonPredicate(column, path, value))
+	return &copy
+}
+
+// WithInSubquery is a helper function to construct functional options that matches the column with the rows
+// selected by sub from subTable, like team_id IN (SELECT id FROM teams WHERE active = $1).
+// The sub Fields should select a single column and the args are numbered with the outer query args.
+func (f *FindAllOptions) WithInSubquery(column string, sub *FindOptions, subTable string) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, inSubqueryPredicate(column, sub, subTable))
+	return &copy
+}
+
+// WithNumericTextFilter is a helper function to construct functional options that compares a text column
+// as a number, like CAST(column AS INTEGER) > $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte",
+// the castType must be a numeric type supported by the flavor CAST, like "SIGNED" on MySQL and "INTEGER" on PostgreSQL.
+func (f *FindAllOptions) WithNumericTextFilter(column, op string, value interface{}, castType string) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, numericTextPredicate(column, op, value, castType))
+	return &copy
+}
+
+// WithTimeInInterval is a helper function to construct functional options that matches the rows whose [startCol, endCol) interval contains value.
+func (f *FindAllOptions) WithTimeInInterval(value interface{}, startCol, endCol string) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, timeInIntervalPredicate(value, startCol, endCol))
+	return &copy
+}
+
+// WithAllowedColumns is a helper function to construct functional options that sets AllowedColumns field.
+// When it is set the filters and orders on other columns are skipped, use the E functions to get an ErrInvalidColumn
+// error instead. The operator suffix is ignored, so the "id.gte" filter checks "id", TieBreaker is not checked.
+func (f *FindAllOptions) WithAllowedColumns(columns ...string) *FindAllOptions {
+	copy := *f
+	copy.AllowedColumns = columns
+	return &copy
+}
+
+// WithCtidFilter is a helper function to construct functional options that matches the PostgreSQL row with
+// the physical location ctid, like "(0,1)". It sets the "ctid.tid" filter, a malformed ctid is an ErrInvalidValue error
+// and the other flavors an ErrUnsupportedOperator error on the E functions.
+func (f *FindAllOptions) WithCtidFilter(ctid string) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters["ctid.tid"] = ctid
+	return &copy
+}
+
+// WithDateIn is a helper function to construct functional options that sets a "field.in" filter
+// parsing the comma separated dates with layout, DateLayout is used when layout is empty.
+func (f *FindAllOptions) WithDateIn(field string, csv string, layout string) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".in"] = TypedIn{Values: csv, Type: "date", Layout: layout}
+	return &copy
+}
+
+// WithTableAlias is a helper function to construct functional options that sets TableAlias field.
+// The table is rendered as "table AS alias" and the bare columns of Fields, filters, orders and the
+// soft delete column are qualified with alias. A filter key like "teams.id" or "teams.id.in" is kept
+// as a qualified column when the alias is set.
+func (f *FindAllOptions) WithTableAlias(alias string) *FindAllOptions {
+	copy := *f
+	copy.TableAlias = alias
+	return &copy
+}
+
+// WithValuesJoin is a helper function to construct functional options that appends an INNER JOIN of the rows
+// to ValuesJoins field, like JOIN (VALUES ($1, $2), ($3, $4)) AS v (id, weight) ON players.id = v.id.
+// The rows are joined on the first column and their args come before the filters args. MySQL uses the
+// VALUES ROW(...) form, SQLite and MariaDB use a UNION ALL of SELECT.
+func (f *FindAllOptions) WithValuesJoin(alias string, columns []string, rows [][]interface{}) *FindAllOptions {
+	copy := *f
+	join := ValuesJoin{Alias: alias, Columns: columns, Rows: rows}
+	copy.ValuesJoins = append(append(make([]ValuesJoin, 0, len(f.ValuesJoins)+1), f.ValuesJoins...), join)
+	return &copy
+}
+
+// WithQualifiedStar is a helper function to construct functional options that selects all the columns of table,
+// like SELECT players.*, teams.name FROM players JOIN teams, to avoid ambiguous columns on joins. The default "*"
+// field is replaced and the qualified stars are kept before the other fields, in the order they were added.
+func (f *FindAllOptions) WithQualifiedStar(table string) *FindAllOptions {
+	copy := *f
+	fields := f.Fields
+	if len(fields) == 1 && fields[0] == "*" {
+		fields = nil
+	}
+	i := 0
+	for i < len(fields) && strings.HasSuffix(fields[i], ".*") {
+		i++
+	}
+	copy.Fields = make([]string, 0, len(fields)+1)
+	copy.Fields = append(copy.Fields, fields[:i]...)
+	copy.Fields = append(copy.Fields, table+".*")
+	copy.Fields = append(copy.Fields, fields[i:]...)
+	return &copy
+}
+
+// WithJoin is a helper function to construct functional options that appends an INNER JOIN of table on onExpr to Joins field.
+func (f *FindAllOptions) WithJoin(table, onExpr string) *FindAllOptions {
+	copy := *f
+	copy.Joins = append(append(make([]Join, 0, len(f.Joins)+1), f.Joins...), Join{Table: table, On: onExpr})
+	return &copy
+}
+
+// WithRoundedFilter is a helper function to construct functional options that compares the column rounded
+// to decimals with value, like ROUND(amount, 2) = $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte",
+// PostgreSQL only rounds numeric columns to decimals.
+func (f *FindAllOptions) WithRoundedFilter(column string, decimals int, op string, value interface{}) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, roundedPredicate(column, decimals, op, value))
+	return &copy
+}
+
+// WithSearch is a helper function to construct functional options that matches the rows with any of the columns
+// containing value, like (name LIKE $1 OR nickname LIKE $2). The value is escaped with EscapeLike and bound once
+// per column, an empty value is ignored.
+func (f *FindAllOptions) WithSearch(value string, columns ...string) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, searchPredicate(value, columns))
+	return &copy
+}
+
+// WithColumnComparison is a helper function to construct functional options that compares two columns,
+// like start_date < end_date, without binding right as an arg. The op is one of "=", "<>", "!=", "<", "<=", ">" and ">=".
+func (f *FindAllOptions) WithColumnComparison(left, op, right string) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, columnComparisonPredicate(left, op, right))
+	return &copy
+}
+
+// WithNonASCIIFilter is a helper function to construct functional options that matches the rows with any
+// non-ASCII character in the column, like name !~ '^[[:ascii:]]*$' on PostgreSQL and
+// name <> CONVERT(name USING ascii) on MySQL and MariaDB. SQLite is not supported.
+func (f *FindAllOptions) WithNonASCIIFilter(column string) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, nonASCIIPredicate(column))
+	return &copy
+}
+
+// WithDateEqualsFilter is a helper function to construct functional options that matches the rows whose
+// timestamp column is in the day of date, ignoring the time, like created_at::date = $1 on PostgreSQL
+// and DATE(created_at) = ? on MySQL, MariaDB and SQLite. The date is bound as "2006-01-02".
+func (f *FindAllOptions) WithDateEqualsFilter(column string, date time.Time) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, dateEqualsPredicate(column, date))
+	return &copy
+}
+
+// WithArrayIndexFilter is a helper function to construct functional options that compares the element of the
+// PostgreSQL array column at index with value, like tags[1] = $1. The op is one of "" (equal), "not", "gt", "gte",
+// "lt" and "lte", the index starts at 1 like the PostgreSQL arrays and the other flavors return ErrUnsupportedFlavor.
+func (f *FindAllOptions) WithArrayIndexFilter(column string, index int, op string, value interface{}) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, arrayIndexPredicate(column, index, op, value))
+	return &copy
+}
+
+// WithMaxInSize is a helper function to construct functional options that sets MaxInSize field.
+// The "in" and "notin" filters with more than n values are skipped, use the E functions to get
+// an ErrInvalidValue error instead. Zero means no limit.
+func (f *FindAllOptions) WithMaxInSize(n int) *FindAllOptions {
+	copy := *f
+	copy.MaxInSize = n
+	return &copy
+}
+
+// WithExists is a helper function to construct functional options that matches the rows with a related subTable row,
+// like EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND minute > $1). The correlationExpr
+// relates subTable with the outer table, the bare subFilters columns are resolved against subTable first
+// and the args are numbered with the outer query args.
+func (f *FindAllOptions) WithExists(subTable, correlationExpr string, subFilters map[string]interface{}) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, existsPredicate(subTable, correlationExpr, copyValues(subFilters), false))
+	return &copy
+}
+
+// WithNotExists is a helper function to construct functional options that matches the rows without a related
+// subTable row, like NOT EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND minute > $1).
+// It takes the same arguments as WithExists.
+func (f *FindAllOptions) WithNotExists(subTable, correlationExpr string, subFilters map[string]interface{}) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, existsPredicate(subTable, correlationExpr, copyValues(subFilters), true))
+	return &copy
+}
+
+// WithNullSafeEqual is a helper function to construct functional options that matches the field equal to value
+// where NULL is equal to NULL, like name <=> ? on MySQL and MariaDB, name IS NOT DISTINCT FROM $1 on PostgreSQL
+// and name IS ? on SQLite. It sets the "field.notdistinctfrom" filter.
+func (f *FindAllOptions) WithNullSafeEqual(field string, value interface{}) *FindAllOptions {
+	copy := *f
+	copy.Filters = copyValues(f.Filters)
+	copy.Filters[field+".notdistinctfrom"] = value
+	return &copy
+}
+
+// WithSkipNil is a helper function to construct functional options that sets SkipNil field.
+// The filters with a nil value or a nil pointer are skipped instead of matching NULL with IS NULL,
+// so a map of optional parameters can be used as the filters.
+func (f *FindAllOptions) WithSkipNil() *FindAllOptions {
+	copy := *f
+	copy.SkipNil = true
+	return &copy
+}
+
+// WithGreatestFilter is a helper function to construct functional options that compares the greatest of the columns
+// with value, like GREATEST(home_goals, away_goals) > $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte".
+// SQLite uses the multi-argument MAX function. PostgreSQL ignores the NULL columns, MySQL, MariaDB and SQLite
+// return NULL when any column is NULL.
+func (f *FindAllOptions) WithGreatestFilter(columns []string, op string, value interface{}) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, extremePredicate(columns, false, op, value))
+	return &copy
+}
+
+// WithLeastFilter is a helper function to construct functional options that compares the least of the columns
+// with value, like LEAST(home_goals, away_goals) > $1. It works like WithGreatestFilter and SQLite uses
+// the multi-argument MIN function.
+func (f *FindAllOptions) WithLeastFilter(columns []string, op string, value interface{}) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, extremePredicate(columns, true, op, value))
+	return &copy
+}
+
+// WithRangeListFilter is a helper function to construct functional options that matches the field between
+// the inclusive bounds of any of the ranges, like (age BETWEEN $1 AND $2 OR age BETWEEN $3 AND $4).
+// An empty ranges list follows EmptyIn like an empty "in" filter.
+func (f *FindAllOptions) WithRangeListFilter(field string, ranges [][2]interface{}) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, rangeListPredicate(field, ranges))
+	return &copy
+}
+
+// Optimize returns a copy of the options with the redundant "in" and "notin" Filters collapsed: the duplicate values
+// are removed, "id.in" is dropped when "id" is one of its values and a single value "id.in" becomes "id".
+// The values are compared by their text, so "id" 5 matches "id.in" "5,6". The other filters and the ones
+// added with helpers like WithCondition are kept as they are.
+func (f *FindAllOptions) Optimize() *FindAllOptions {
+	copy := *f
+	copy.Filters = optimizeFilters(f.Filters)
+	return &copy
+}
+
+// WithTextLikeFilter is a helper function to construct functional options that matches the rows whose column
+// cast to text contains pattern, like id::text LIKE '%42%' on PostgreSQL, CAST(id AS CHAR) on MySQL and MariaDB
+// and CAST(id AS TEXT) on SQLite, to search numeric or uuid columns. The LIKE metacharacters of pattern are
+// escaped and an empty pattern is ignored.
+func (f *FindAllOptions) WithTextLikeFilter(column, pattern string) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, textLikePredicate(column, pattern))
+	return &copy
+}
+
+// WithEpochFilter is a helper function to construct functional options that compares the column storing unix epoch
+// seconds with t, like created_at > $1 with t.Unix() bound. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte".
+func (f *FindAllOptions) WithEpochFilter(column, op string, t time.Time) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, epochPredicate(column, op, t))
+	return &copy
+}
+
+// WithCondition is a helper function to construct functional options that adds the boolean filter tree condition,
+// like (a = $1 AND (b = $2 OR c = $3)) for AndCondition(FilterCondition("a", 1), OrCondition(FilterCondition("b", 2),
+// FilterCondition("c", 3))). The leaves use the same keys and operators of Filters field.
+func (f *FindAllOptions) WithCondition(condition Condition) *FindAllOptions {
+	copy := *f
+	copy.predicates = appendPredicate(f.predicates, conditionPredicate(condition))
+	return &copy
+}
+
+func (f *FindAllOptions) lockConfig() lockConfig {
+	if f.ForShare {
+		return lockConfig{forShare: true, mode: f.ForShareMode, wait: f.LockWait}
+	}
+	return lockConfig{forUpdate: f.ForUpdate, mode: f.ForUpdateMode, wait: f.LockWait}
+}
+
+// orderBy returns the ORDER BY expression of OrderBy followed by Orders and the TieBreaker column
+// when it is not already ordered by, the invalid OrderBy and Orders are skipped and the first error is returned.
+func (f *FindAllOptions) orderBy() (string, error) {
+	var exprs []string
+	var firstErr error
+	if f.OrderBy != "" {
+		if err := checkOrderBy(f.OrderBy, f.AllowedColumns); err != nil {
+			firstErr = err
+		} else {
+			exprs = append(exprs, f.OrderBy)
+		}
+	}
+	for _, order := range f.Orders {
+		expr, err := order.expr()
+		if err == nil && len(f.AllowedColumns) > 0 && !containsString(f.AllowedColumns, order.Column) {
+			err = fmt.Errorf("%w: %q", ErrInvalidColumn, order.Column)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		exprs = append(exprs, qualifyColumn(f.TableAlias, expr))
+	}
+	if tieBreaker := qualifyColumn(f.TableAlias, f.TieBreaker); f.TieBreaker != "" && !orderedBy(exprs, f.TieBreaker) && !orderedBy(exprs, tieBreaker) {
+		exprs = append(exprs, tieBreaker)
+	}
+	return strings.Join(exprs, ", "), firstErr
+}
+
+// randomOrder returns the ORDER BY expression of the seeded random order with the seed added to cond,
+// it is empty when RandomSeedColumn is not set or is invalid.
+func (f *FindAllOptions) randomOrder(cond *sqlbuilder.Cond) (string, error) {
+	if f.RandomSeedColumn == "" {
+		return "", nil
+	}
+	if !isColumnName(f.RandomSeedColumn) || (len(f.AllowedColumns) > 0 && !containsString(f.AllowedColumns, f.RandomSeedColumn)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidColumn, f.RandomSeedColumn)
+	}
+	column := qualifyColumn(f.TableAlias, f.RandomSeedColumn)
+	switch f.Flavor {
+	case PostgreSQLFlavor:
+		return fmt.Sprintf("md5(%s::text || %s)", column, cond.Var(f.RandomSeed)), nil
+	case MySQLFlavor, MariaDBFlavor:
+		return fmt.Sprintf("MD5(CONCAT(%s, %s))", column, cond.Var(f.RandomSeed)), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFlavor, f.RandomSeedColumn)
+}
+
+// cursorTokenExpr returns the WHERE expression that selects the rows after the CursorToken values
+// with the values added to cond, it is empty when CursorToken is not set or is invalid.
+func (f *FindAllOptions) cursorTokenExpr(cond *sqlbuilder.Cond) (string, error) {
+	if f.CursorToken == "" {
+		return "", nil
+	}
+	values, err := DecodeCursor(f.CursorToken)
+	if err != nil {
+		return "", err
+	}
+	orders := append(make([]OrderBy, 0, len(f.Orders)+1), f.Orders...)
+	tieBreaker := f.TieBreaker != ""
+	for _, order := range f.Orders {
+		tieBreaker = tieBreaker && order.Column != f.TieBreaker
+	}
+	if tieBreaker {
+		orders = append(orders, OrderBy{Column: f.TieBreaker})
+	}
+	if len(orders) == 0 {
+		return "", ErrMissingOrderBy
+	}
+	for _, order := range orders {
+		if _, err := order.expr(); err != nil {
+			return "", err
+		}
+		if _, ok := values[order.Column]; !ok {
+			return "", fmt.Errorf("%w: cursor token: missing %q", ErrInvalidValue, order.Column)
+		}
+	}
+	exprs := make([]string, len(orders))
+	for i, order := range orders {
+		parts := make([]string, 0, i+1)
+		for _, previous := range orders[:i] {
+			parts = append(parts, cond.Equal(qualifyColumn(f.TableAlias, previous.Column), values[previous.Column]))
+		}
+		column := qualifyColumn(f.TableAlias, order.Column)
+		if order.Direction == Desc {
+			parts = append(parts, cond.LessThan(column, values[order.Column]))
+		} else {
+			parts = append(parts, cond.GreaterThan(column, values[order.Column]))
+		}
+		if len(parts) == 1 {
+			exprs[i] = parts[0]
+		} else {
+			exprs[i] = cond.And(parts...)
+		}
+	}
+	return cond.Or(exprs...), nil
+}
+
+// unordered returns a copy of the options without OrderBy, Orders, TieBreaker, the seeded random order
+// and the CursorToken that depends on them.
+func (f *FindAllOptions) unordered() FindAllOptions {
+	copy := *f
+	copy.CursorToken = ""
+	copy.OrderBy = ""
+	copy.Orders = nil
+	copy.TieBreaker = ""
+	copy.RandomSeedColumn = ""
+	copy.RandomSeed = ""
+	return copy
+}
+
+// selectModifiers are the tokens allowed in SelectPrefix.
+var selectModifiers = []string{
+	"DISTINCT", "ALL", "DISTINCTROW", "HIGH_PRIORITY", "STRAIGHT_JOIN", "SQL_SMALL_RESULT",
+	"SQL_BIG_RESULT", "SQL_BUFFER_RESULT", "SQL_NO_CACHE", "SQL_CALC_FOUND_ROWS",
+}
+
+// orderFields returns fields with the ones in order moved to the front in that order, followed by the others.
+// A field is matched by its text or by the alias of an "expr AS alias" field,
+// ErrInvalidColumn is returned when a field of order is not in fields.
+func orderFields(fields, order []string) ([]string, error) {
+	ordered := make([]string, 0, len(fields))
+	used := make([]bool, len(fields))
+	for _, name := range order {
+		found := false
+		for i, field := range fields {
+			if !used[i] && (field == name || fieldAlias(field) == name) {
+				ordered = append(ordered, field)
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidColumn, name)
+		}
+	}
+	for i, field := range fields {
+		if !used[i] {
+			ordered = append(ordered, field)
+		}
+	}
+	return ordered, nil
+}
+
+// fieldAlias returns the unquoted alias of an "expr AS alias" field or an empty string.
+func fieldAlias(field string) string {
+	i := strings.LastIndex(field, " AS ")
+	if i < 0 {
+		return ""
+	}
+	return strings.Trim(field[i+len(" AS "):], "`\"")
+}
+
+// fields returns Fields qualified with TableAlias and prefixed with the SelectPrefix tokens,
+// the tokens are upper cased and the ones that are not select modifiers are skipped and the first error is returned.
+func (f *FindAllOptions) fields() ([]string, error) {
+	if len(f.FieldOrder) > 0 {
+		fields, err := orderFields(f.Fields, f.FieldOrder)
+		if err != nil {
+			return f.Fields, err
+		}
+		copy := *f
+		copy.Fields = fields
+		copy.FieldOrder = nil
+		return copy.fields()
+	}
+	if len(f.SelectPrefix) == 0 || len(f.Fields) == 0 {
+		return f.Fields, nil
+	}
+	var tokens []string
+	var firstErr error
+	for _, token := range f.SelectPrefix {
+		if !containsString(selectModifiers, strings.ToUpper(token)) {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%w: %q", ErrInvalidValue, token)
+			}
+			continue
+		}
+		tokens = append(tokens, strings.ToUpper(token))
+	}
+	if len(tokens) == 0 {
+		return f.Fields, firstErr
+	}
+	fields := append(make([]string, 0, len(f.Fields)), qualifyFields(f.TableAlias, f.Fields)...)
+	fields[0] = strings.Join(tokens, " ") + " " + fields[0]
+	return fields, firstErr
+}
+
+func (f *FindAllOptions) filterConfig() filterConfig {
+	return filterConfig{flavor: f.Flavor, emptyIn: f.EmptyIn, qualifier: f.TableAlias, allowedColumns: f.AllowedColumns, maxInSize: f.MaxInSize, skipNil: f.SkipNil}
+}
+
+// NewFindAllOptions returns a FindAllOptions with opts applied in order.
+// The opts change the returned options in place, unlike the With* methods that copy the options on every call.
+func NewFindAllOptions(flavor Flavor, opts ...FindAllOption) *FindAllOptions {
+	options := &FindAllOptions{
+		Flavor:  flavor,
+		Filters: make(map[string]interface{}),
+	}
+	options.star[0] = "*"
+	options.Fields = options.star[:]
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// CombineOptions returns a copy of base with the filters and predicates of extra added, so both sets are combined with AND.
+// The extra filters can't override the base ones, a filter key of extra that is already in base is added as a separate
+// condition instead. Limit, Offset and the order (OrderBy, Orders, TieBreaker and the seeded random order)
+// of extra replace the base ones when they are set, the other fields are kept from base.
+func CombineOptions(base, extra *FindAllOptions) *FindAllOptions {
+	combined := *base
+	combined.Filters = copyValues(base.Filters)
+	var conflicts map[string]interface{}
+	for key, value := range extra.Filters {
+		if _, ok := base.Filters[key]; !ok {
+			combined.Filters[key] = value
+			continue
+		}
+		if conflicts == nil {
+			conflicts = make(map[string]interface{})
+		}
+		conflicts[key] = value
+	}
+	combined.predicates = append(append(make([]predicate, 0, len(base.predicates)+len(extra.predicates)+1), base.predicates...), extra.predicates...)
+	if conflicts != nil {
+		combined.predicates = append(combined.predicates, filterGroupPredicate(conflicts))
+	}
+	if extra.Limit != 0 {
+		combined.Limit = extra.Limit
+	}
+	if extra.Offset != 0 {
+		combined.Offset = extra.Offset
+	}
+	if extra.OrderBy != "" || len(extra.Orders) > 0 || extra.TieBreaker != "" || extra.RandomSeedColumn != "" {
+		combined.OrderBy = extra.OrderBy
+		combined.Orders = extra.Orders
+		combined.TieBreaker = extra.TieBreaker
+		combined.RandomSeedColumn = extra.RandomSeedColumn
+		combined.RandomSeed = extra.RandomSeed
+	}
+	return &combined
+}
+
+// FindAllOption is a functional option for NewFindAllOptions function.
+type FindAllOption func(*FindAllOptions)
+
+// WithFields sets Fields field.
+func WithFields(fields ...string) FindAllOption {
+	return func(f *FindAllOptions) {
+		f.Fields = fields
+	}
+}
+
+// WithFilter sets the field filter, the field may have an operator suffix like "id.in".
+func WithFilter(field string, value interface{}) FindAllOption {
+	return func(f *FindAllOptions) {
+		f.Filters[field] = value
+	}
+}
+
+// WithLimit sets Limit field.
+func WithLimit(limit int) FindAllOption {
+	return func(f *FindAllOptions) {
+		f.Limit = limit
+	}
+}
+
+// WithOffset sets Offset field.
+func WithOffset(offset int) FindAllOption {
+	return func(f *FindAllOptions) {
+		f.Offset = offset
+	}
+}
+
+// WithOrderBy sets OrderBy field.
+func WithOrderBy(orderBy string) FindAllOption {
+	return func(f *FindAllOptions) {
+		f.OrderBy = orderBy
+	}
+}
+
+// UpdateOptions provides configuration for UpdateWithOptionsQuery function.
+type UpdateOptions struct {
+	Flavor         Flavor
+	Assignments    map[string]interface{}
+	Filters        map[string]interface{}
+	EmptyIn        EmptyInBehavior
+	Schema         string
+	Returning      []string
+	VirtualColumns []string
+	AllowedColumns []string
+	MaxInSize      int
+	SkipNil        bool
+	predicates     []predicate
+	ChangedOnly    bool
+	Joins          []Join
+	Limit          int
+}
+
+// WithAssignment is a helper function to construct functional options that sets assignments.
+func (u *UpdateOptions) WithAssignment(field string, value interface{}) *UpdateOptions {
+	copy := *u
+	copy.Assignments = copyValues(u.Assignments)
+	copy.Assignments[field] = value
+	return &copy
+}
+
+// WithFilter is a helper function to construct functional options that sets Filters field.
+func (u *UpdateOptions) WithFilter(field string, value interface{}) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters[field] = value
+	return &copy
+}
+
+// WithFilters is a helper function to construct functional options that merges filters into Filters field,
+// the existing filters with the same keys are replaced.
+func (u *UpdateOptions) WithFilters(filters map[string]interface{}) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	for key, value := range filters {
+		copy.Filters[key] = value
+	}
+	return &copy
+}
+
+// WithRangeFilter is a helper function to construct functional options that sets a range on Filters field.
+// The bounds use ">=" and "<=" when inclusive and ">" and "<" otherwise.
+func (u *UpdateOptions) WithRangeFilter(field string, low, high interface{}, lowInclusive, highInclusive bool) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	setRangeFilter(copy.Filters, field, low, high, lowInclusive, highInclusive)
+	return &copy
+}
+
+// WithEmptyIn is a helper function to construct functional options that sets EmptyIn field.
+func (u *UpdateOptions) WithEmptyIn(behavior EmptyInBehavior) *UpdateOptions {
+	copy := *u
+	copy.EmptyIn = behavior
+	return &copy
+}
+
+// WithBlankFilter is a helper function to construct functional options that matches NULL or empty string values.
+// It sets the "field.blank" filter.
+func (u *UpdateOptions) WithBlankFilter(field string) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters[field+".blank"] = true
+	return &copy
+}
+
+// WithSchema is a helper function to construct functional options that sets Schema field.
+// The schema and the table name are quoted for the flavor.
+func (u *UpdateOptions) WithSchema(schema string) *UpdateOptions {
+	copy := *u
+	copy.Schema = schema
+	return &copy
+}
+
+// WithStartsWithAny is a helper function to construct functional options that matches any of the prefixes.
+// It sets the "field.startswithany" filter, the prefixes are escaped with EscapeLike.
+func (u *UpdateOptions) WithStartsWithAny(field string, prefixes ...string) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters[field+".startswithany"] = prefixes
+	return &copy
+}
+
+// WithFloatApproxFilter is a helper function to construct functional options that matches floats approximately.
+// It sets the "column.approx" filter.
+func (u *UpdateOptions) WithFloatApproxFilter(column string, value, epsilon float64) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters[column+".approx"] = Approx{Value: value, Epsilon: epsilon}
+	return &copy
+}
+
+// WithTypedIn is a helper function to construct functional options that sets a "field.in" filter
+// converting the comma separated values to goType, see TypedIn.
+func (u *UpdateOptions) WithTypedIn(field string, csv string, goType string) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters[field+".in"] = TypedIn{Values: csv, Type: goType}
+	return &copy
+}
+
+// WithLikeAnyArray is a helper function to construct functional options that matches any of the LIKE patterns.
+// It sets the "field.likeany" filter, PostgreSQL uses LIKE ANY(ARRAY[...]) and the other flavors OR-ed LIKEs.
+func (u *UpdateOptions) WithLikeAnyArray(field string, patterns []string) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters[field+".likeany"] = patterns
+	return &copy
+}
+
+// WithJoin is a helper function to construct functional options that appends a join of table on onExpr to Joins field,
+// to update the rows matching the joined rows. It renders UPDATE players SET ... FROM rankings WHERE onExpr on
+// PostgreSQL and SQLite 3.33+ and UPDATE players JOIN rankings ON onExpr SET ... on MySQL and MariaDB.
+// The filter keys may be qualified with the joined table, like "rankings.season", and the bare ones are
+// qualified with the updated table.
+func (u *UpdateOptions) WithJoin(table, onExpr string) *UpdateOptions {
+	copy := *u
+	copy.Joins = append(append(make([]Join, 0, len(u.Joins)+1), u.Joins...), Join{Table: table, On: onExpr})
+	return &copy
+}
+
+// WithReturning is a helper function to construct functional options that sets Returning field.
+// RETURNING is supported on PostgreSQL and SQLite 3.35+, UpdateWithOptionsQueryE returns ErrReturningNotSupported on MySQL and MariaDB.
+func (u *UpdateOptions) WithReturning(columns ...string) *UpdateOptions {
+	copy := *u
+	copy.Returning = columns
+	return &copy
+}
+
+// WithReturningStruct is a helper function to construct functional options that sets Returning field
+// to the columns of structValue with tag in the declared order, all the columns are used when tag is empty.
+func (u *UpdateOptions) WithReturningStruct(tag string, structValue interface{}) *UpdateOptions {
+	return u.WithReturning(structColumns(tag, structValue)...)
+}
+
+// WithVirtualColumns is a helper function to construct functional options that sets VirtualColumns field.
+// It is only a hint used by NormalizedFilters to flag the filters on generated columns, the SQL is not changed.
+func (u *UpdateOptions) WithVirtualColumns(columns ...string) *UpdateOptions {
+	copy := *u
+	copy.VirtualColumns = columns
+	return &copy
+}
+
+// NormalizedFilters returns the filters split into field and operator, sorted by key.
+func (u *UpdateOptions) NormalizedFilters() []NormalizedFilter {
+	return normalizeFilters(u.Filters, u.VirtualColumns)
+}
+
+// WithUUIDFilter is a helper function to construct functional options that matches a UUID column.
+// It sets the "field.uuid" filter, a malformed value is an ErrInvalidValue error on the E functions.
+func (u *UpdateOptions) WithUUIDFilter(field string, value string) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters[field+".uuid"] = value
+	return &copy
+}
+
+// WithNotGroup is a helper function to construct functional options that excludes the rows matching all filters,
+// like NOT (a = 1 AND b = 2). The filters use the same keys and operators of Filters field.
+func (u *UpdateOptions) WithNotGroup(filters map[string]interface{}) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, notGroupPredicate(filters))
+	return &copy
+}
+
+// WithJSONFilter is a helper function to construct functional options that compares the dot separated path
+// of the JSON column with value, like data#>>$1::text[] with the {status} path bound on PostgreSQL and JSON_EXTRACT(data, ?) with the "$.status" path bound on MySQL.
+func (u *UpdateOptions) WithJSONFilter(column, path string, value interface{}) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, jsonPredicate(column, path, value))
+	return &copy
+}
+
+// WithInSubquery is a helper function to construct functional options that matches the column with the rows
+// selected by sub from subTable, like team_id IN (SELECT id FROM teams WHERE active = $1).
+// The sub Fields should select a single column and the args are numbered with the outer query args.
+func (u *UpdateOptions) WithInSubquery(column string, sub *FindOptions, subTable string) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, inSubqueryPredicate(column, sub, subTable))
+	return &copy
+}
+
+// WithNumericTextFilter is a helper function to construct functional options that compares a text column
+// as a number, like CAST(column AS INTEGER) > $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte",
+// the castType must be a numeric type supported by the flavor CAST, like "SIGNED" on MySQL and "INTEGER" on PostgreSQL.
+func (u *UpdateOptions) WithNumericTextFilter(column, op string, value interface{}, castType string) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, numericTextPredicate(column, op, value, castType))
+	return &copy
+}
+
+// WithChangedOnly is a helper function to construct functional options that sets ChangedOnly field.
+// Only the rows with at least one column different from the assignments are updated,
+// using the null safe comparison of the flavor, so the no-op updates don't fire triggers or bump timestamps.
+func (u *UpdateOptions) WithChangedOnly() *UpdateOptions {
+	copy := *u
+	copy.ChangedOnly = true
+	return &copy
+}
+
+// WithTimeInInterval is a helper function to construct functional options that matches the rows whose [startCol, endCol) interval contains value.
+func (u *UpdateOptions) WithTimeInInterval(value interface{}, startCol, endCol string) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, timeInIntervalPredicate(value, startCol, endCol))
+	return &copy
+}
+
+// WithAllowedColumns is a helper function to construct functional options that sets AllowedColumns field.
+// When it is set the filters on other columns are skipped, use the E functions to get an ErrInvalidColumn error instead.
+// The operator suffix is ignored, so the "id.gte" filter checks "id".
+func (u *UpdateOptions) WithAllowedColumns(columns ...string) *UpdateOptions {
+	copy := *u
+	copy.AllowedColumns = columns
+	return &copy
+}
+
+// WithCtidFilter is a helper function to construct functional options that matches the PostgreSQL row with
+// the physical location ctid, like "(0,1)". It sets the "ctid.tid" filter, a malformed ctid is an ErrInvalidValue error
+// and the other flavors an ErrUnsupportedOperator error on the E functions.
+func (u *UpdateOptions) WithCtidFilter(ctid string) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters["ctid.tid"] = ctid
+	return &copy
+}
+
+// WithDateIn is a helper function to construct functional options that sets a "field.in" filter
+// parsing the comma separated dates with layout, DateLayout is used when layout is empty.
+func (u *UpdateOptions) WithDateIn(field string, csv string, layout string) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters[field+".in"] = TypedIn{Values: csv, Type: "date", Layout: layout}
+	return &copy
+}
+
+// WithRoundedFilter is a helper function to construct functional options that compares the column rounded
+// to decimals with value, like ROUND(amount, 2) = $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte",
+// PostgreSQL only rounds numeric columns to decimals.
+func (u *UpdateOptions) WithRoundedFilter(column string, decimals int, op string, value interface{}) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, roundedPredicate(column, decimals, op, value))
+	return &copy
+}
+
+// WithSearch is a helper function to construct functional options that matches the rows with any of the columns
+// containing value, like (name LIKE $1 OR nickname LIKE $2). The value is escaped with EscapeLike and bound once
+// per column, an empty value is ignored.
+func (u *UpdateOptions) WithSearch(value string, columns ...string) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, searchPredicate(value, columns))
+	return &copy
+}
+
+// WithColumnComparison is a helper function to construct functional options that compares two columns,
+// like start_date < end_date, without binding right as an arg. The op is one of "=", "<>", "!=", "<", "<=", ">" and ">=".
+func (u *UpdateOptions) WithColumnComparison(left, op, right string) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, columnComparisonPredicate(left, op, right))
+	return &copy
+}
+
+// WithNonASCIIFilter is a helper function to construct functional options that matches the rows with any
+// non-ASCII character in the column, like name !~ '^[[:ascii:]]*$' on PostgreSQL and
+// name <> CONVERT(name USING ascii) on MySQL and MariaDB. SQLite is not supported.
+func (u *UpdateOptions) WithNonASCIIFilter(column string) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, nonASCIIPredicate(column))
+	return &copy
+}
+
+// WithDateEqualsFilter is a helper function to construct functional options that matches the rows whose
+// timestamp column is in the day of date, ignoring the time, like created_at::date = $1 on PostgreSQL
+// and DATE(created_at) = ? on MySQL, MariaDB and SQLite. The date is bound as "2006-01-02".
+func (u *UpdateOptions) WithDateEqualsFilter(column string, date time.Time) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, dateEqualsPredicate(column, date))
+	return &copy
+}
+
+// WithArrayIndexFilter is a helper function to construct functional options that compares the element of the
+// PostgreSQL array column at index with value, like tags[1] = $1. The op is one of "" (equal), "not", "gt", "gte",
+// "lt" and "lte", the index starts at 1 like the PostgreSQL arrays and the other flavors return ErrUnsupportedFlavor.
+func (u *UpdateOptions) WithArrayIndexFilter(column string, index int, op string, value interface{}) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, arrayIndexPredicate(column, index, op, value))
+	return &copy
+}
+
+// WithMaxInSize is a helper function to construct functional options that sets MaxInSize field.
+// The "in" and "notin" filters with more than n values are skipped, use the E functions to get
+// an ErrInvalidValue error instead. Zero means no limit.
+func (u *UpdateOptions) WithMaxInSize(n int) *UpdateOptions {
+	copy := *u
+	copy.MaxInSize = n
+	return &copy
+}
+
+// WithExists is a helper function to construct functional options that matches the rows with a related subTable row,
+// like EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND minute > $1). The correlationExpr
+// relates subTable with the outer table, the bare subFilters columns are resolved against subTable first
+// and the args are numbered with the outer query args.
+func (u *UpdateOptions) WithExists(subTable, correlationExpr string, subFilters map[string]interface{}) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, existsPredicate(subTable, correlationExpr, copyValues(subFilters), false))
+	return &copy
+}
+
+// WithNotExists is a helper function to construct functional options that matches the rows without a related
+// subTable row, like NOT EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND minute > $1).
+// It takes the same arguments as WithExists.
+func (u *UpdateOptions) WithNotExists(subTable, correlationExpr string, subFilters map[string]interface{}) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, existsPredicate(subTable, correlationExpr, copyValues(subFilters), true))
+	return &copy
+}
+
+// WithNullSafeEqual is a helper function to construct functional options that matches the field equal to value
+// where NULL is equal to NULL, like name <=> ? on MySQL and MariaDB, name IS NOT DISTINCT FROM $1 on PostgreSQL
+// and name IS ? on SQLite. It sets the "field.notdistinctfrom" filter.
+func (u *UpdateOptions) WithNullSafeEqual(field string, value interface{}) *UpdateOptions {
+	copy := *u
+	copy.Filters = copyValues(u.Filters)
+	copy.Filters[field+".notdistinctfrom"] = value
+	return &copy
+}
+
+// WithSkipNil is a helper function to construct functional options that sets SkipNil field.
+// The filters with a nil value or a nil pointer are skipped instead of matching NULL with IS NULL,
+// so a map of optional parameters can be used as the filters.
+func (u *UpdateOptions) WithSkipNil() *UpdateOptions {
+	copy := *u
+	copy.SkipNil = true
+	return &copy
+}
+
+// WithGreatestFilter is a helper function to construct functional options that compares the greatest of the columns
+// with value, like GREATEST(home_goals, away_goals) > $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte".
+// SQLite uses the multi-argument MAX function. PostgreSQL ignores the NULL columns, MySQL, MariaDB and SQLite
+// return NULL when any column is NULL.
+func (u *UpdateOptions) WithGreatestFilter(columns []string, op string, value interface{}) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, extremePredicate(columns, false, op, value))
+	return &copy
+}
+
+// WithLeastFilter is a helper function to construct functional options that compares the least of the columns
+// with value, like LEAST(home_goals, away_goals) > $1. It works like WithGreatestFilter and SQLite uses
+// the multi-argument MIN function.
+func (u *UpdateOptions) WithLeastFilter(columns []string, op string, value interface{}) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, extremePredicate(columns, true, op, value))
+	return &copy
+}
+
+// WithRangeListFilter is a helper function to construct functional options that matches the field between
+// the inclusive bounds of any of the ranges, like (age BETWEEN $1 AND $2 OR age BETWEEN $3 AND $4).
+// An empty ranges list follows EmptyIn like an empty "in" filter.
+func (u *UpdateOptions) WithRangeListFilter(field string, ranges [][2]interface{}) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, rangeListPredicate(field, ranges))
+	return &copy
+}
+
+// Optimize returns a copy of the options with the redundant Filters collapsed, like FindAllOptions.Optimize.
+// The Assignments are not changed.
+func (u *UpdateOptions) Optimize() *UpdateOptions {
+	copy := *u
+	copy.Filters = optimizeFilters(u.Filters)
+	return &copy
+}
+
+// WithTextLikeFilter is a helper function to construct functional options that matches the rows whose column
+// cast to text contains pattern, like id::text LIKE '%42%' on PostgreSQL, CAST(id AS CHAR) on MySQL and MariaDB
+// and CAST(id AS TEXT) on SQLite, to search numeric or uuid columns. The LIKE metacharacters of pattern are
+// escaped and an empty pattern is ignored.
+func (u *UpdateOptions) WithTextLikeFilter(column, pattern string) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, textLikePredicate(column, pattern))
+	return &copy
+}
+
+// WithLimit is a helper function to construct functional options that sets Limit field, to change at most limit
+// rows in batches. LIMIT is only rendered on MySQL and MariaDB, the other flavors ignore it and the E variant
+// returns ErrUnsupportedFlavor. The multi-table UPDATE of WithJoin doesn't allow LIMIT, ErrInvalidLimit is returned.
+func (u *UpdateOptions) WithLimit(limit int) *UpdateOptions {
+	copy := *u
+	copy.Limit = limit
+	return &copy
+}
+
+// WithEpochFilter is a helper function to construct functional options that compares the column storing unix epoch
+// seconds with t, like created_at > $1 with t.Unix() bound. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte".
+func (u *UpdateOptions) WithEpochFilter(column, op string, t time.Time) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, epochPredicate(column, op, t))
+	return &copy
+}
+
+// WithCondition is a helper function to construct functional options that adds the boolean filter tree condition,
+// like (a = $1 AND (b = $2 OR c = $3)) for AndCondition(FilterCondition("a", 1), OrCondition(FilterCondition("b", 2),
+// FilterCondition("c", 3))). The leaves use the same keys and operators of Filters field.
+func (u *UpdateOptions) WithCondition(condition Condition) *UpdateOptions {
+	copy := *u
+	copy.predicates = appendPredicate(u.predicates, conditionPredicate(condition))
+	return &copy
+}
+
+func (u *UpdateOptions) filterConfig() filterConfig {
+	return filterConfig{flavor: u.Flavor, emptyIn: u.EmptyIn, allowedColumns: u.AllowedColumns, maxInSize: u.MaxInSize, skipNil: u.SkipNil}
+}
+
+// NewUpdateOptions returns a UpdateOptions.
 func NewUpdateOptions(flavor Flavor) *UpdateOptions {
 	return &UpdateOptions{
 		Flavor:      flavor,
@@ -146,17 +1950,400 @@ func NewUpdateOptions(flavor Flavor) *UpdateOptions {
 
 // DeleteOptions provides configuration for DeleteWithOptionsQuery function.
 type DeleteOptions struct {
-	Flavor  Flavor
-	Filters map[string]interface{}
+	Flavor         Flavor
+	Filters        map[string]interface{}
+	EmptyIn        EmptyInBehavior
+	Schema         string
+	Returning      []string
+	Joins          []Join
+	Limit          int
+	VirtualColumns []string
+	AllowedColumns []string
+	MaxInSize      int
+	SkipNil        bool
+	predicates     []predicate
 }
 
 // WithFilter is a helper function to construct functional options that sets Filters field.
 func (d *DeleteOptions) WithFilter(field string, value interface{}) *DeleteOptions {
 	copy := *d
+	copy.Filters = copyValues(d.Filters)
 	copy.Filters[field] = value
 	return &copy
 }
 
+// WithFilters is a helper function to construct functional options that merges filters into Filters field,
+// the existing filters with the same keys are replaced.
+func (d *DeleteOptions) WithFilters(filters map[string]interface{}) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	for key, value := range filters {
+		copy.Filters[key] = value
+	}
+	return &copy
+}
+
+// WithRangeFilter is a helper function to construct functional options that sets a range on Filters field.
+// The bounds use ">=" and "<=" when inclusive and ">" and "<" otherwise.
+func (d *DeleteOptions) WithRangeFilter(field string, low, high interface{}, lowInclusive, highInclusive bool) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	setRangeFilter(copy.Filters, field, low, high, lowInclusive, highInclusive)
+	return &copy
+}
+
+// WithEmptyIn is a helper function to construct functional options that sets EmptyIn field.
+func (d *DeleteOptions) WithEmptyIn(behavior EmptyInBehavior) *DeleteOptions {
+	copy := *d
+	copy.EmptyIn = behavior
+	return &copy
+}
+
+// WithBlankFilter is a helper function to construct functional options that matches NULL or empty string values.
+// It sets the "field.blank" filter.
+func (d *DeleteOptions) WithBlankFilter(field string) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	copy.Filters[field+".blank"] = true
+	return &copy
+}
+
+// WithSchema is a helper function to construct functional options that sets Schema field.
+// The schema and the table name are quoted for the flavor.
+func (d *DeleteOptions) WithSchema(schema string) *DeleteOptions {
+	copy := *d
+	copy.Schema = schema
+	return &copy
+}
+
+// WithStartsWithAny is a helper function to construct functional options that matches any of the prefixes.
+// It sets the "field.startswithany" filter, the prefixes are escaped with EscapeLike.
+func (d *DeleteOptions) WithStartsWithAny(field string, prefixes ...string) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	copy.Filters[field+".startswithany"] = prefixes
+	return &copy
+}
+
+// WithFloatApproxFilter is a helper function to construct functional options that matches floats approximately.
+// It sets the "column.approx" filter.
+func (d *DeleteOptions) WithFloatApproxFilter(column string, value, epsilon float64) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	copy.Filters[column+".approx"] = Approx{Value: value, Epsilon: epsilon}
+	return &copy
+}
+
+// WithTypedIn is a helper function to construct functional options that sets a "field.in" filter
+// converting the comma separated values to goType, see TypedIn.
+func (d *DeleteOptions) WithTypedIn(field string, csv string, goType string) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	copy.Filters[field+".in"] = TypedIn{Values: csv, Type: goType}
+	return &copy
+}
+
+// WithLikeAnyArray is a helper function to construct functional options that matches any of the LIKE patterns.
+// It sets the "field.likeany" filter, PostgreSQL uses LIKE ANY(ARRAY[...]) and the other flavors OR-ed LIKEs.
+func (d *DeleteOptions) WithLikeAnyArray(field string, patterns []string) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	copy.Filters[field+".likeany"] = patterns
+	return &copy
+}
+
+// WithJoin is a helper function to construct functional options that appends a join of table on onExpr to Joins field,
+// to delete the rows matching the joined rows. It renders DELETE FROM players USING teams WHERE onExpr on PostgreSQL,
+// DELETE players FROM players JOIN teams ON onExpr on MySQL and MariaDB and a rowid IN subquery on SQLite.
+// The filter keys may be qualified with the joined table, like "teams.disbanded", and the bare ones are
+// qualified with the deleted table.
+func (d *DeleteOptions) WithJoin(table, onExpr string) *DeleteOptions {
+	copy := *d
+	copy.Joins = append(append(make([]Join, 0, len(d.Joins)+1), d.Joins...), Join{Table: table, On: onExpr})
+	return &copy
+}
+
+// WithReturning is a helper function to construct functional options that sets Returning field.
+// RETURNING is supported on PostgreSQL, SQLite 3.35+ and MariaDB without Joins, DeleteWithOptionsQueryE returns ErrReturningNotSupported otherwise.
+func (d *DeleteOptions) WithReturning(columns ...string) *DeleteOptions {
+	copy := *d
+	copy.Returning = columns
+	return &copy
+}
+
+// WithReturningStruct is a helper function to construct functional options that sets Returning field
+// to the columns of structValue with tag in the declared order, all the columns are used when tag is empty.
+func (d *DeleteOptions) WithReturningStruct(tag string, structValue interface{}) *DeleteOptions {
+	return d.WithReturning(structColumns(tag, structValue)...)
+}
+
+// WithVirtualColumns is a helper function to construct functional options that sets VirtualColumns field.
+// It is only a hint used by NormalizedFilters to flag the filters on generated columns, the SQL is not changed.
+func (d *DeleteOptions) WithVirtualColumns(columns ...string) *DeleteOptions {
+	copy := *d
+	copy.VirtualColumns = columns
+	return &copy
+}
+
+// NormalizedFilters returns the filters split into field and operator, sorted by key.
+func (d *DeleteOptions) NormalizedFilters() []NormalizedFilter {
+	return normalizeFilters(d.Filters, d.VirtualColumns)
+}
+
+// WithUUIDFilter is a helper function to construct functional options that matches a UUID column.
+// It sets the "field.uuid" filter, a malformed value is an ErrInvalidValue error on the E functions.
+func (d *DeleteOptions) WithUUIDFilter(field string, value string) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	copy.Filters[field+".uuid"] = value
+	return &copy
+}
+
+// WithNotGroup is a helper function to construct functional options that excludes the rows matching all filters,
+// like NOT (a = 1 AND b = 2). The filters use the same keys and operators of Filters field.
+func (d *DeleteOptions) WithNotGroup(filters map[string]interface{}) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, notGroupPredicate(filters))
+	return &copy
+}
+
+// WithJSONFilter is a helper function to construct functional options that compares the dot separated path
+// of the JSON column with value, like data#>>$1::text[] with the {status} path bound on PostgreSQL and JSON_EXTRACT(data, ?) with the "$.status" path bound on MySQL.
+func (d *DeleteOptions) WithJSONFilter(column, path string, value interface{}) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, jsonPredicate(column, path, value))
+	return &copy
+}
+
+// WithInSubquery is a helper function to construct functional options that matches the column with the rows
+// selected by sub from subTable, like team_id IN (SELECT id FROM teams WHERE active = $1).
+// The sub Fields should select a single column and the args are numbered with the outer query args.
+func (d *DeleteOptions) WithInSubquery(column string, sub *FindOptions, subTable string) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, inSubqueryPredicate(column, sub, subTable))
+	return &copy
+}
+
+// WithNumericTextFilter is a helper function to construct functional options that compares a text column
+// as a number, like CAST(column AS INTEGER) > $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte",
+// the castType must be a numeric type supported by the flavor CAST, like "SIGNED" on MySQL and "INTEGER" on PostgreSQL.
+func (d *DeleteOptions) WithNumericTextFilter(column, op string, value interface{}, castType string) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, numericTextPredicate(column, op, value, castType))
+	return &copy
+}
+
+// WithTimeInInterval is a helper function to construct functional options that matches the rows whose [startCol, endCol) interval contains value.
+func (d *DeleteOptions) WithTimeInInterval(value interface{}, startCol, endCol string) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, timeInIntervalPredicate(value, startCol, endCol))
+	return &copy
+}
+
+// WithAllowedColumns is a helper function to construct functional options that sets AllowedColumns field.
+// When it is set the filters on other columns are skipped, use the E functions to get an ErrInvalidColumn error instead.
+// The operator suffix is ignored, so the "id.gte" filter checks "id".
+func (d *DeleteOptions) WithAllowedColumns(columns ...string) *DeleteOptions {
+	copy := *d
+	copy.AllowedColumns = columns
+	return &copy
+}
+
+// WithCtidFilter is a helper function to construct functional options that matches the PostgreSQL row with
+// the physical location ctid, like "(0,1)". It sets the "ctid.tid" filter, a malformed ctid is an ErrInvalidValue error
+// and the other flavors an ErrUnsupportedOperator error on the E functions.
+func (d *DeleteOptions) WithCtidFilter(ctid string) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	copy.Filters["ctid.tid"] = ctid
+	return &copy
+}
+
+// WithDateIn is a helper function to construct functional options that sets a "field.in" filter
+// parsing the comma separated dates with layout, DateLayout is used when layout is empty.
+func (d *DeleteOptions) WithDateIn(field string, csv string, layout string) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	copy.Filters[field+".in"] = TypedIn{Values: csv, Type: "date", Layout: layout}
+	return &copy
+}
+
+// WithRoundedFilter is a helper function to construct functional options that compares the column rounded
+// to decimals with value, like ROUND(amount, 2) = $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte",
+// PostgreSQL only rounds numeric columns to decimals.
+func (d *DeleteOptions) WithRoundedFilter(column string, decimals int, op string, value interface{}) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, roundedPredicate(column, decimals, op, value))
+	return &copy
+}
+
+// WithSearch is a helper function to construct functional options that matches the rows with any of the columns
+// containing value, like (name LIKE $1 OR nickname LIKE $2). The value is escaped with EscapeLike and bound once
+// per column, an empty value is ignored.
+func (d *DeleteOptions) WithSearch(value string, columns ...string) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, searchPredicate(value, columns))
+	return &copy
+}
+
+// WithColumnComparison is a helper function to construct functional options that compares two columns,
+// like start_date < end_date, without binding right as an arg. The op is one of "=", "<>", "!=", "<", "<=", ">" and ">=".
+func (d *DeleteOptions) WithColumnComparison(left, op, right string) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, columnComparisonPredicate(left, op, right))
+	return &copy
+}
+
+// WithNonASCIIFilter is a helper function to construct functional options that matches the rows with any
+// non-ASCII character in the column, like name !~ '^[[:ascii:]]*$' on PostgreSQL and
+// name <> CONVERT(name USING ascii) on MySQL and MariaDB. SQLite is not supported.
+func (d *DeleteOptions) WithNonASCIIFilter(column string) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, nonASCIIPredicate(column))
+	return &copy
+}
+
+// WithDateEqualsFilter is a helper function to construct functional options that matches the rows whose
+// timestamp column is in the day of date, ignoring the time, like created_at::date = $1 on PostgreSQL
+// and DATE(created_at) = ? on MySQL, MariaDB and SQLite. The date is bound as "2006-01-02".
+func (d *DeleteOptions) WithDateEqualsFilter(column string, date time.Time) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, dateEqualsPredicate(column, date))
+	return &copy
+}
+
+// WithArrayIndexFilter is a helper function to construct functional options that compares the element of the
+// PostgreSQL array column at index with value, like tags[1] = $1. The op is one of "" (equal), "not", "gt", "gte",
+// "lt" and "lte", the index starts at 1 like the PostgreSQL arrays and the other flavors return ErrUnsupportedFlavor.
+func (d *DeleteOptions) WithArrayIndexFilter(column string, index int, op string, value interface{}) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, arrayIndexPredicate(column, index, op, value))
+	return &copy
+}
+
+// WithMaxInSize is a helper function to construct functional options that sets MaxInSize field.
+// The "in" and "notin" filters with more than n values are skipped, use the E functions to get
+// an ErrInvalidValue error instead. Zero means no limit.
+func (d *DeleteOptions) WithMaxInSize(n int) *DeleteOptions {
+	copy := *d
+	copy.MaxInSize = n
+	return &copy
+}
+
+// WithExists is a helper function to construct functional options that matches the rows with a related subTable row,
+// like EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND minute > $1). The correlationExpr
+// relates subTable with the outer table, the bare subFilters columns are resolved against subTable first
+// and the args are numbered with the outer query args.
+func (d *DeleteOptions) WithExists(subTable, correlationExpr string, subFilters map[string]interface{}) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, existsPredicate(subTable, correlationExpr, copyValues(subFilters), false))
+	return &copy
+}
+
+// WithNotExists is a helper function to construct functional options that matches the rows without a related
+// subTable row, like NOT EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND minute > $1).
+// It takes the same arguments as WithExists.
+func (d *DeleteOptions) WithNotExists(subTable, correlationExpr string, subFilters map[string]interface{}) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, existsPredicate(subTable, correlationExpr, copyValues(subFilters), true))
+	return &copy
+}
+
+// WithNullSafeEqual is a helper function to construct functional options that matches the field equal to value
+// where NULL is equal to NULL, like name <=> ? on MySQL and MariaDB, name IS NOT DISTINCT FROM $1 on PostgreSQL
+// and name IS ? on SQLite. It sets the "field.notdistinctfrom" filter.
+func (d *DeleteOptions) WithNullSafeEqual(field string, value interface{}) *DeleteOptions {
+	copy := *d
+	copy.Filters = copyValues(d.Filters)
+	copy.Filters[field+".notdistinctfrom"] = value
+	return &copy
+}
+
+// WithSkipNil is a helper function to construct functional options that sets SkipNil field.
+// The filters with a nil value or a nil pointer are skipped instead of matching NULL with IS NULL,
+// so a map of optional parameters can be used as the filters.
+func (d *DeleteOptions) WithSkipNil() *DeleteOptions {
+	copy := *d
+	copy.SkipNil = true
+	return &copy
+}
+
+// WithGreatestFilter is a helper function to construct functional options that compares the greatest of the columns
+// with value, like GREATEST(home_goals, away_goals) > $1. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte".
+// SQLite uses the multi-argument MAX function. PostgreSQL ignores the NULL columns, MySQL, MariaDB and SQLite
+// return NULL when any column is NULL.
+func (d *DeleteOptions) WithGreatestFilter(columns []string, op string, value interface{}) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, extremePredicate(columns, false, op, value))
+	return &copy
+}
+
+// WithLeastFilter is a helper function to construct functional options that compares the least of the columns
+// with value, like LEAST(home_goals, away_goals) > $1. It works like WithGreatestFilter and SQLite uses
+// the multi-argument MIN function.
+func (d *DeleteOptions) WithLeastFilter(columns []string, op string, value interface{}) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, extremePredicate(columns, true, op, value))
+	return &copy
+}
+
+// WithRangeListFilter is a helper function to construct functional options that matches the field between
+// the inclusive bounds of any of the ranges, like (age BETWEEN $1 AND $2 OR age BETWEEN $3 AND $4).
+// An empty ranges list follows EmptyIn like an empty "in" filter.
+func (d *DeleteOptions) WithRangeListFilter(field string, ranges [][2]interface{}) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, rangeListPredicate(field, ranges))
+	return &copy
+}
+
+// Optimize returns a copy of the options with the redundant Filters collapsed, like FindAllOptions.Optimize.
+func (d *DeleteOptions) Optimize() *DeleteOptions {
+	copy := *d
+	copy.Filters = optimizeFilters(d.Filters)
+	return &copy
+}
+
+// WithTextLikeFilter is a helper function to construct functional options that matches the rows whose column
+// cast to text contains pattern, like id::text LIKE '%42%' on PostgreSQL, CAST(id AS CHAR) on MySQL and MariaDB
+// and CAST(id AS TEXT) on SQLite, to search numeric or uuid columns. The LIKE metacharacters of pattern are
+// escaped and an empty pattern is ignored.
+func (d *DeleteOptions) WithTextLikeFilter(column, pattern string) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, textLikePredicate(column, pattern))
+	return &copy
+}
+
+// WithLimit is a helper function to construct functional options that sets Limit field, to change at most limit
+// rows in batches. LIMIT is only rendered on MySQL and MariaDB, the other flavors ignore it and the E variant
+// returns ErrUnsupportedFlavor. It can't be combined with WithJoin, MySQL rejects LIMIT on a multi-table DELETE,
+// nor with CascadeDeleteQuery, whose children statements would not be limited to the deleted rows.
+func (d *DeleteOptions) WithLimit(limit int) *DeleteOptions {
+	copy := *d
+	copy.Limit = limit
+	return &copy
+}
+
+// WithEpochFilter is a helper function to construct functional options that compares the column storing unix epoch
+// seconds with t, like created_at > $1 with t.Unix() bound. The op is one of "" (equal), "not", "gt", "gte", "lt" and "lte".
+func (d *DeleteOptions) WithEpochFilter(column, op string, t time.Time) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, epochPredicate(column, op, t))
+	return &copy
+}
+
+// WithCondition is a helper function to construct functional options that adds the boolean filter tree condition,
+// like (a = $1 AND (b = $2 OR c = $3)) for AndCondition(FilterCondition("a", 1), OrCondition(FilterCondition("b", 2),
+// FilterCondition("c", 3))). The leaves use the same keys and operators of Filters field.
+func (d *DeleteOptions) WithCondition(condition Condition) *DeleteOptions {
+	copy := *d
+	copy.predicates = appendPredicate(d.predicates, conditionPredicate(condition))
+	return &copy
+}
+
+func (d *DeleteOptions) filterConfig() filterConfig {
+	return filterConfig{flavor: d.Flavor, emptyIn: d.EmptyIn, allowedColumns: d.AllowedColumns, maxInSize: d.MaxInSize, skipNil: d.SkipNil}
+}
+
 // NewDeleteOptions returns a DeleteOptions.
 func NewDeleteOptions(flavor Flavor) *DeleteOptions {
 	return &DeleteOptions{
@@ -164,3 +2351,271 @@ func NewDeleteOptions(flavor Flavor) *DeleteOptions {
 		Filters: make(map[string]interface{}),
 	}
 }
+
+// UpsertOptions provides configuration for UpsertQuery function.
+type UpsertOptions struct {
+	Flavor             Flavor
+	ConflictColumns    []string
+	ConflictConstraint string
+	ConflictWhere      string
+	UpdateColumns      []string
+	Returning          []string
+}
+
+// WithConflictColumns is a helper function to construct functional options that sets ConflictColumns field.
+func (u *UpsertOptions) WithConflictColumns(columns ...string) *UpsertOptions {
+	copy := *u
+	copy.ConflictColumns = columns
+	return &copy
+}
+
+// WithConflictConstraint is a helper function to construct functional options that sets ConflictConstraint field.
+// PostgreSQLFlavor renders "ON CONFLICT ON CONSTRAINT name" instead of the conflict columns, use it to target
+// a unique constraint created with NULLS NOT DISTINCT (PostgreSQL 15+) or a constraint over expressions.
+// The other flavors ignore it.
+func (u *UpsertOptions) WithConflictConstraint(name string) *UpsertOptions {
+	copy := *u
+	copy.ConflictConstraint = name
+	return &copy
+}
+
+// WithConflictWhere is a helper function to construct functional options that sets ConflictWhere field.
+// PostgreSQLFlavor renders the predicate after the conflict columns, like "ON CONFLICT (email) WHERE deleted_at IS NULL",
+// to target a partial unique index. It is ignored with ConflictConstraint and by the other flavors.
+func (u *UpsertOptions) WithConflictWhere(predicate string) *UpsertOptions {
+	copy := *u
+	copy.ConflictWhere = predicate
+	return &copy
+}
+
+// WithUpdateColumns is a helper function to construct functional options that sets UpdateColumns field.
+// When empty, all tagged columns except the conflict columns are updated.
+func (u *UpsertOptions) WithUpdateColumns(columns ...string) *UpsertOptions {
+	copy := *u
+	copy.UpdateColumns = columns
+	return &copy
+}
+
+// WithReturning is a helper function to construct functional options that sets Returning field.
+func (u *UpsertOptions) WithReturning(columns ...string) *UpsertOptions {
+	copy := *u
+	copy.Returning = columns
+	return &copy
+}
+
+// WithReturningStruct is a helper function to construct functional options that sets Returning field
+// to the columns of structValue with tag in the declared order, all the columns are used when tag is empty.
+func (u *UpsertOptions) WithReturningStruct(tag string, structValue interface{}) *UpsertOptions {
+	return u.WithReturning(structColumns(tag, structValue)...)
+}
+
+// NewUpsertOptions returns a UpsertOptions.
+func NewUpsertOptions(flavor Flavor) *UpsertOptions {
+	return &UpsertOptions{
+		Flavor: flavor,
+	}
+}
+
+func setRangeFilter(filters map[string]interface{}, field string, low, high interface{}, lowInclusive, highInclusive bool) {
+	delete(filters, field+".gt")
+	delete(filters, field+".gte")
+	delete(filters, field+".lt")
+	delete(filters, field+".lte")
+	if lowInclusive {
+		filters[field+".gte"] = low
+	} else {
+		filters[field+".gt"] = low
+	}
+	if highInclusive {
+		filters[field+".lte"] = high
+	} else {
+		filters[field+".lt"] = high
+	}
+}
+
+// copyValues returns a copy of values, so the options copies don't share the map.
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values)+1)
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
+// optimizeFilters returns a copy of filters with the redundant "in" and "notin" filters collapsed,
+// the values are compared with sameValue:
+//   - duplicate values are removed from the "field.in" and "field.notin" lists;
+//   - "field.in" is dropped when "field" is set to one of its values, since field = 5 AND field IN (5, 6) is field = 5;
+//   - "field.in" with a single value becomes the equality filter "field" when "field" is not set.
+//
+// TypedIn values and any other filter are kept as they are.
+func optimizeFilters(filters map[string]interface{}) map[string]interface{} {
+	optimized := copyValues(filters)
+	for _, key := range sortedKeys(filters) {
+		var field string
+		switch {
+		case strings.HasSuffix(key, ".in"):
+			field = strings.TrimSuffix(key, ".in")
+		case strings.HasSuffix(key, ".notin"):
+			field = strings.TrimSuffix(key, ".notin")
+		default:
+			continue
+		}
+		if _, ok := filters[key].(TypedIn); ok {
+			continue
+		}
+		values, err := parseInValues(filters[key])
+		if err != nil || values == nil {
+			continue
+		}
+		values = uniqueValues(values)
+		optimized[key] = values
+		if strings.HasSuffix(key, ".notin") {
+			continue
+		}
+		if equal, ok := filters[field]; ok {
+			if equal != nil && containsValue(values, equal) {
+				delete(optimized, key)
+			}
+			continue
+		}
+		if len(values) == 1 {
+			delete(optimized, key)
+			optimized[field] = values[0]
+		}
+	}
+	return optimized
+}
+
+// uniqueValues returns values without the duplicates, keeping the first occurrence order.
+func uniqueValues(values []interface{}) []interface{} {
+	unique := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		if !containsValue(unique, value) {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// containsValue reports whether values has an element equal to value, see sameValue.
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if sameValue(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue reports whether a and b are deeply equal or are scalars with the same text, like 5 and "5",
+// since the comma separated "in" values are parsed as strings.
+func sameValue(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	return isScalar(a) && isScalar(b) && fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// isScalar reports whether value is a bool, number or string.
+func isScalar(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// structColumns returns the columns of structValue with tag, or all the columns when tag is empty.
+func structColumns(tag string, structValue interface{}) []string {
+	theStruct := sqlbuilder.NewStruct(structValue)
+	if tag == "" {
+		return theStruct.Columns()
+	}
+	return theStruct.ColumnsForTag(tag)
+}
+
+// appendPredicate returns a copy of predicates with p appended, so the options copies don't share the backing array.
+func appendPredicate(predicates []predicate, p predicate) []predicate {
+	return append(append(make([]predicate, 0, len(predicates)+1), predicates...), p)
+}
+
+// orderedBy reports if column is the first word of any comma separated ORDER BY expression of exprs.
+func orderedBy(exprs []string, column string) bool {
+	for _, expr := range exprs {
+		for _, part := range strings.Split(expr, ",") {
+			if fields := strings.Fields(part); len(fields) > 0 && strings.EqualFold(fields[0], column) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isColumnName reports if name is a column name with an optional table qualifier,
+// made only of letters, digits and underscores.
+func isColumnName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, part := range strings.Split(name, ".") {
+		if part == "" {
+			return false
+		}
+		for i := 0; i < len(part); i++ {
+			c := part[i]
+			if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkOrderBy returns an error if the allowed columns are set and orderBy is not a list of
+// "column [ASC|DESC]" expressions of allowed columns.
+func checkOrderBy(orderBy string, allowedColumns []string) error {
+	if len(allowedColumns) == 0 {
+		return nil
+	}
+	for _, part := range strings.Split(orderBy, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 || len(fields) > 2 || !containsString(allowedColumns, fields[0]) {
+			return fmt.Errorf("%w: %q", ErrInvalidColumn, strings.TrimSpace(part))
+		}
+		if len(fields) == 2 && !strings.EqualFold(fields[1], "asc") && !strings.EqualFold(fields[1], "desc") {
+			return fmt.Errorf("%w: %q", ErrInvalidDirection, fields[1])
+		}
+	}
+	return nil
+}
+
+// qualifyColumn prefixes column with the table name or alias when it is set and column is a bare column name.
+func qualifyColumn(table, column string) string {
+	if table == "" || column == "" || strings.Contains(column, ".") || !isColumnName(strings.Fields(column)[0]) {
+		return column
+	}
+	return table + "." + column
+}
+
+// qualifyFields returns a copy of fields with the bare column names qualified with table, "*" and expressions are kept.
+func qualifyFields(table string, fields []string) []string {
+	if table == "" {
+		return fields
+	}
+	qualified := make([]string, len(fields))
+	for i := range fields {
+		if isColumnName(fields[i]) {
+			qualified[i] = qualifyColumn(table, fields[i])
+		} else {
+			qualified[i] = fields[i]
+		}
+	}
+	return qualified
+}
@@ -52,3 +52,194 @@ func TestFindAllOptions(t *testing.T) {
 		assert.Equal(t, "column asc", options.OrderBy)
 	})
 }
+
+func TestNormalizedFilters(t *testing.T) {
+	options := NewFindAllOptions(MySQLFlavor).
+		WithVirtualColumns("full_name").
+		WithFilter("full_name.like", "Ronaldo%").
+		WithFilter("id.in", "1,2").
+		WithFilter("team_id", 1)
+	expected := []NormalizedFilter{
+		{Key: "full_name.like", Field: "full_name", Operator: "like", Value: "Ronaldo%", Virtual: true},
+		{Key: "id.in", Field: "id", Operator: "in", Value: "1,2"},
+		{Key: "team_id", Field: "team_id", Value: 1},
+	}
+	assert.Equal(t, expected, options.NormalizedFilters())
+
+	sqlQuery, _ := FindAllQuery("players", options)
+	assert.Equal(t, "SELECT * FROM players WHERE full_name LIKE ? AND id IN (?, ?) AND team_id = ?", sqlQuery)
+}
+
+func TestNewFindAllOptionsFunctional(t *testing.T) {
+	chain := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"id", "name"}).
+		WithFilter("team_id", 1).
+		WithFilter("age.gte", 18).
+		WithLimit(10).
+		WithOffset(20).
+		WithOrderBy("id asc")
+	functional := NewFindAllOptions(
+		PostgreSQLFlavor,
+		WithFields("id", "name"),
+		WithFilter("team_id", 1),
+		WithFilter("age.gte", 18),
+		WithLimit(10),
+		WithOffset(20),
+		WithOrderBy("id asc"),
+	)
+	assert.Equal(t, chain, functional)
+	chainSQL, chainArgs := FindAllQuery("players", chain)
+	functionalSQL, functionalArgs := FindAllQuery("players", functional)
+	assert.Equal(t, chainSQL, functionalSQL)
+	assert.Equal(t, chainArgs, functionalArgs)
+
+	opts := []FindAllOption{WithFields("id"), WithLimit(10), WithOffset(20), WithOrderBy("id asc")}
+	base := testing.AllocsPerRun(100, func() { NewFindAllOptions(PostgreSQLFlavor) })
+	withOpts := testing.AllocsPerRun(100, func() { NewFindAllOptions(PostgreSQLFlavor, opts...) })
+	assert.Equal(t, base, withOpts)
+}
+
+func TestWithFilterCopyOnWrite(t *testing.T) {
+	base := NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1)
+	active := base.WithFilter("active", true)
+	inactive := base.WithFilter("active", false).WithRangeFilter("age", 18, 30, true, true)
+	assert.Equal(t, map[string]interface{}{"team_id": 1}, base.Filters)
+	assert.Equal(t, map[string]interface{}{"team_id": 1, "active": true}, active.Filters)
+	assert.Equal(t, map[string]interface{}{"team_id": 1, "active": false, "age.gte": 18, "age.lte": 30}, inactive.Filters)
+
+	update := NewUpdateOptions(PostgreSQLFlavor).WithAssignment("name", "Ronaldinho")
+	renamed := update.WithAssignment("name", "Ronaldo").WithFilter("id", 1)
+	assert.Equal(t, map[string]interface{}{"name": "Ronaldinho"}, update.Assignments)
+	assert.Equal(t, map[string]interface{}{}, update.Filters)
+	assert.Equal(t, map[string]interface{}{"name": "Ronaldo"}, renamed.Assignments)
+
+	deleteOptions := NewDeleteOptions(MySQLFlavor)
+	deleteOptions.WithFilter("id", 1)
+	assert.Equal(t, map[string]interface{}{}, deleteOptions.Filters)
+}
+
+func TestWithFilters(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithFilters(map[string]interface{}{"team_id": 1, "age.gte": 18})
+		assert.Equal(t, map[string]interface{}{"team_id": 1, "age.gte": 18}, options.Filters)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		base := NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1).WithFilter("active", true)
+		options := base.WithFilters(map[string]interface{}{"team_id": 2, "age.gte": 18})
+		assert.Equal(t, map[string]interface{}{"team_id": 2, "active": true, "age.gte": 18}, options.Filters)
+		assert.Equal(t, map[string]interface{}{"team_id": 1, "active": true}, base.Filters)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		filters := map[string]interface{}{"id.in": "1,2"}
+		assert.Equal(t, filters, NewUpdateOptions(MySQLFlavor).WithFilters(filters).Filters)
+		assert.Equal(t, filters, NewDeleteOptions(MySQLFlavor).WithFilters(filters).Filters)
+	})
+}
+
+func TestCombineOptions(t *testing.T) {
+	base := NewFindAllOptions(PostgreSQLFlavor).
+		WithFilter("tenant_id", 1).
+		WithOrderBy("id").
+		WithLimit(50)
+	extra := NewFindAllOptions(PostgreSQLFlavor).
+		WithFilter("name.like", "R%").
+		WithFilter("tenant_id", 2).
+		WithSearch("ron", "nickname").
+		WithOffset(50)
+	options := CombineOptions(base, extra)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE name LIKE $1 AND tenant_id = $2 AND (nickname LIKE $3) AND (tenant_id = $4) ORDER BY id LIMIT 50 OFFSET 50`, sqlQuery)
+	assert.Equal(t, []interface{}{"R%", 1, "%ron%", 2}, args)
+	assert.Equal(t, map[string]interface{}{"tenant_id": 1}, base.Filters)
+
+	options = CombineOptions(base, NewFindAllOptions(PostgreSQLFlavor).WithOrderBy("name DESC").WithLimit(10))
+	sqlQuery, args = FindAllQuery("players", options)
+	assert.Equal(t, `SELECT * FROM players WHERE tenant_id = $1 ORDER BY name DESC LIMIT 10 OFFSET 0`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestWithReturningStruct(t *testing.T) {
+	r10 := player{ID: 1, Name: "Ronaldinho"}
+
+	updateOptions := NewUpdateOptions(PostgreSQLFlavor).WithAssignment("name", "R10").WithFilter("id", 1).WithReturningStruct("", &r10)
+	assert.Equal(t, []string{"id", "name"}, updateOptions.Returning)
+	sqlQuery, args := UpdateWithOptionsQuery("players", updateOptions)
+	assert.Equal(t, `UPDATE players SET name = $1 WHERE id = $2 RETURNING id, name`, sqlQuery)
+	assert.Equal(t, []interface{}{"R10", 1}, args)
+
+	deleteOptions := NewDeleteOptions(PostgreSQLFlavor).WithFilter("id", 1).WithReturningStruct("update", r10)
+	assert.Equal(t, []string{"name"}, deleteOptions.Returning)
+
+	upsertOptions := NewUpsertOptions(PostgreSQLFlavor).WithConflictColumns("id").WithReturningStruct("insert", &r10)
+	assert.Equal(t, []string{"id", "name"}, upsertOptions.Returning)
+}
+
+func TestOptimize(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFilter("id", 5).
+		WithFilter("id.in", []int{5, 6}).
+		WithFilter("status.in", []interface{}{"active", "active"}).
+		WithFilter("role.notin", "admin,admin,owner").
+		Optimize()
+	assert.Equal(t, map[string]interface{}{
+		"id":         5,
+		"status":     "active",
+		"role.notin": []interface{}{"admin", "owner"},
+	}, options.Filters)
+
+	sqlQuery, args := FindAllQuery("users", options)
+	assert.Equal(t, `SELECT * FROM users WHERE id = $1 AND role NOT IN ($2, $3) AND status = $4`, sqlQuery)
+	assert.Equal(t, []interface{}{5, "admin", "owner", "active"}, args)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithFilter("id", 7).WithFilter("id.in", []int{5, 6}).Optimize()
+	assert.Equal(t, map[string]interface{}{"id": 7, "id.in": []interface{}{5, 6}}, options.Filters)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithFilter("id", 5).WithFilter("id.in", "5").Optimize()
+	assert.Equal(t, map[string]interface{}{"id": 5}, options.Filters)
+	options = NewFindAllOptions(PostgreSQLFlavor).WithFilter("id", 5).WithFilter("id.in", "5,6").Optimize()
+	assert.Equal(t, map[string]interface{}{"id": 5}, options.Filters)
+	options = NewFindAllOptions(PostgreSQLFlavor).WithFilter("id.in", []interface{}{5, "5", 6}).Optimize()
+	assert.Equal(t, map[string]interface{}{"id.in": []interface{}{5, 6}}, options.Filters)
+}
+
+func TestWithFieldOrder(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"id", "name"}).
+		WithField("score * 2", "double_score").
+		WithFieldOrder([]string{"double_score", "name"}).
+		WithFilter("team_id", 1)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT score * 2 AS "double_score", name, id FROM players WHERE team_id = $1`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+
+	_, _, err = FindAllQueryE("players", options.WithFieldOrder([]string{"email"}))
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+}
+
+func TestWithQualifiedStar(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"teams.name"}).
+		WithQualifiedStar("players").
+		WithJoin("teams", "teams.id = players.team_id").
+		WithFilter("active", true)
+	sqlQuery, args := FindAllQuery("players", options)
+	assert.Equal(t, `SELECT players.*, teams.name FROM players JOIN teams ON teams.id = players.team_id WHERE active = $1`, sqlQuery)
+	assert.Equal(t, []interface{}{true}, args)
+
+	sqlQuery, _ = FindQuery("players", NewFindOptions(MySQLFlavor).WithQualifiedStar("players").WithQualifiedStar("teams").WithFilter("id", 1))
+	assert.Equal(t, "SELECT players.*, teams.* FROM players WHERE id = ?", sqlQuery)
+}
+
+func TestWithWindowField(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"id", "team_id", "score"}).
+		WithWindowField("ROW_NUMBER() OVER (PARTITION BY team_id ORDER BY score DESC)", "rank").
+		WithFilter("season", 2024)
+	sqlQuery, args := FindAllQuery("players", options)
+	assert.Equal(t, `SELECT id, team_id, score, ROW_NUMBER() OVER (PARTITION BY team_id ORDER BY score DESC) AS "rank" FROM players WHERE season = $1`, sqlQuery)
+	assert.Equal(t, []interface{}{2024}, args)
+}
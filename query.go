@@ -1,219 +1,2047 @@
 package sqlquery
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/huandu/go-sqlbuilder"
 )
 
+// parseIn returns the comma separated values, the result is sized once and the values are sliced from value.
 func parseIn(value string) []interface{} {
-	values := strings.Split(value, ",")
+	result := make([]interface{}, 0, strings.Count(value, ",")+1)
+	for {
+		i := strings.IndexByte(value, ',')
+		if i < 0 {
+			return append(result, value)
+		}
+		result = append(result, value[:i])
+		value = value[i+1:]
+	}
+}
+
+// TypedIn is a value for the "in" and "notin" filter operators that converts the comma separated Values
+// to Type before binding. The supported types are "int", "int64", "float64", "bool", "time" (RFC 3339)
+// and "date", parsed with Layout or "2006-01-02" when it is empty.
+type TypedIn struct {
+	Values string
+	Type   string
+	Layout string
+}
+
+// DateLayout is the default layout of the "date" TypedIn type.
+const DateLayout = "2006-01-02"
+
+func parseTypedIn(value TypedIn) ([]interface{}, error) {
+	if strings.TrimSpace(value.Values) == "" {
+		return []interface{}{}, nil
+	}
+	values := strings.Split(value.Values, ",")
 	result := make([]interface{}, len(values))
 	for i := range values {
-		result[i] = values[i]
-	}
-	return result
-}
-
-func parseSelectFilter(sb *sqlbuilder.SelectBuilder, key string, value interface{}) {
-	if strings.Contains(key, ".") {
-		split := strings.Split(key, ".")
-		parsedKey := split[0]
-		compare := split[1]
-		switch compare {
-		case "in":
-			valueStr, ok := value.(string)
-			if ok {
-				values := parseIn(valueStr)
-				sb.Where(sb.In(parsedKey, values...))
-			}
-		case "notin":
-			valueStr, ok := value.(string)
-			if ok {
-				values := parseIn(valueStr)
-				sb.Where(sb.NotIn(parsedKey, values...))
-			}
-		case "not":
-			sb.Where(sb.NotEqual(parsedKey, value))
-		case "gt":
-			sb.Where(sb.GreaterThan(parsedKey, value))
-		case "gte":
-			sb.Where(sb.GreaterEqualThan(parsedKey, value))
-		case "lt":
-			sb.Where(sb.LessThan(parsedKey, value))
-		case "lte":
-			sb.Where(sb.LessEqualThan(parsedKey, value))
-		case "like":
-			sb.Where(sb.Like(parsedKey, value))
-		case "null":
-			valueBool, ok := value.(bool)
-			if ok {
-				if valueBool {
-					sb.Where(sb.IsNull(key))
-				} else {
-					sb.Where(sb.IsNotNull(key))
-				}
+		v := strings.TrimSpace(values[i])
+		var err error
+		switch value.Type {
+		case "int":
+			result[i], err = strconv.Atoi(v)
+		case "int64":
+			result[i], err = strconv.ParseInt(v, 10, 64)
+		case "float64":
+			result[i], err = strconv.ParseFloat(v, 64)
+		case "bool":
+			result[i], err = strconv.ParseBool(v)
+		case "time":
+			result[i], err = time.Parse(time.RFC3339, v)
+		case "date":
+			layout := value.Layout
+			if layout == "" {
+				layout = DateLayout
 			}
+			result[i], err = time.Parse(layout, v)
+		default:
+			return nil, fmt.Errorf("unsupported type %q", value.Type)
 		}
-	} else {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// parseInValues returns the values of the "in" and "notin" operators, nil means that the value type is not supported.
+// A slice, like []int or []time.Time, is bound element by element, a []byte is not handled as a slice.
+func parseInValues(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return []interface{}{}, nil
+		}
+		return parseIn(v), nil
+	case TypedIn:
+		return parseTypedIn(v)
+	case []interface{}:
+		return v, nil
+	case []byte:
+		return nil, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return nil, nil
+	}
+	result := make([]interface{}, rv.Len())
+	for i := range result {
+		result[i] = rv.Index(i).Interface()
+	}
+	return result, nil
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes the LIKE metacharacters "%", "_" and the escape character "\" from input,
+// so it can be safely used in a LIKE pattern with user input.
+// PostgreSQL and MySQL use "\" as the default escape character, SQLite requires an explicit ESCAPE '\' clause.
+func EscapeLike(input string) string {
+	return likeReplacer.Replace(input)
+}
+
+// Operators is the list of supported filter operators, used as the key suffix like "id.in".
+// The "popcount" operator compares the number of set bits of the column, with BIT_COUNT on MySQL and MariaDB
+// and bit_count on PostgreSQL 14+, and may be followed by a comparison like "flags.popcount.gte".
+// The "notilike" operator renders NOT ILIKE on PostgreSQL and LOWER(field) NOT LIKE LOWER(pattern) on the other flavors.
+// The "ieq" operator is the case insensitive equality LOWER(field) = LOWER(value), without the LIKE wildcards.
+var Operators = []string{
+	"in", "notin", "not", "gt", "gte", "lt", "lte", "like", "null", "blank",
+	"startswith", "endswith", "contains", "startswithany", "approx", "likeany", "uuid",
+	"arraycontains", "arraycontainedby", "arrayoverlap", "findinset", "jsonkey", "tid", "distinctfrom",
+	"popcount", "notilike", "similarto", "notdistinctfrom", "ieq",
+}
+
+// Condition is a node of the boolean filter tree of WithCondition. A leaf has Key, a filter key with the
+// operators of Filters field like "age.gte", and Value. A group has no Key and combines Children with AND,
+// or with OR when Or is set. Use FilterCondition, AndCondition and OrCondition to build the tree.
+type Condition struct {
+	Key      string
+	Value    interface{}
+	Or       bool
+	Children []Condition
+}
+
+// FilterCondition returns a leaf Condition that filters key with value.
+func FilterCondition(key string, value interface{}) Condition {
+	return Condition{Key: key, Value: value}
+}
+
+// AndCondition returns a Condition that combines children with AND.
+func AndCondition(children ...Condition) Condition {
+	return Condition{Children: children}
+}
+
+// OrCondition returns a Condition that combines children with OR.
+func OrCondition(children ...Condition) Condition {
+	return Condition{Or: true, Children: children}
+}
+
+// JSONKey is the value of the MySQL and MariaDB "jsonkey" filter operator that compares the unquoted value of Key with Value,
+// a string value only checks if the key exists.
+type JSONKey struct {
+	Key   string
+	Value interface{}
+}
+
+// arrayOperators maps the PostgreSQL array filter operators to the sql operators.
+var arrayOperators = map[string]string{
+	"arraycontains":    "@>",
+	"arraycontainedby": "<@",
+	"arrayoverlap":     "&&",
+}
+
+var arrayElementReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// arrayLiteral returns the PostgreSQL array literal of values, like {a,b,c}.
+// The elements with special characters are double quoted.
+func arrayLiteral(values []string) string {
+	elements := make([]string, len(values))
+	for i, value := range values {
+		elements[i] = arrayElement(value)
+	}
+	return "{" + strings.Join(elements, ",") + "}"
+}
+
+// arrayElement returns value as an element of a PostgreSQL array literal, double quoted when it has special characters.
+func arrayElement(value string) string {
+	if value == "" || strings.EqualFold(value, "null") || strings.ContainsAny(value, "{},\"\\ \t\n\r\v\f") {
+		return `"` + arrayElementReplacer.Replace(value) + `"`
+	}
+	return value
+}
+
+// Approx is the value of the "approx" filter operator, it matches when the absolute difference
+// between the column and Value is lower than Epsilon.
+type Approx struct {
+	Value   float64
+	Epsilon float64
+}
+
+var (
+	// ErrUnknownOperator is returned when a filter key uses an operator that is not in Operators.
+	ErrUnknownOperator = errors.New("sqlquery: unknown operator")
+	// ErrReturningNotSupported is returned when a RETURNING clause is requested for a flavor without support.
+	ErrReturningNotSupported = errors.New("sqlquery: returning is not supported by flavor")
+	// ErrUnsupportedOperator is returned when a filter operator is not supported by the flavor.
+	ErrUnsupportedOperator = errors.New("sqlquery: operator is not supported by flavor")
+	// ErrUnsupportedFlavor is returned when a query is not supported by the flavor.
+	ErrUnsupportedFlavor = errors.New("sqlquery: query is not supported by flavor")
+	// ErrInvalidDirection is returned when an order direction is not "asc" or "desc".
+	ErrInvalidDirection = errors.New("sqlquery: invalid direction")
+	// ErrInvalidColumn is returned when a column name is not a plain column name or is not allowed.
+	ErrInvalidColumn = errors.New("sqlquery: invalid column")
+	// ErrInvalidValue is returned when a filter value can't be parsed.
+	ErrInvalidValue = errors.New("sqlquery: invalid value")
+	// ErrInvalidLimit is returned when the limit is negative.
+	ErrInvalidLimit = errors.New("sqlquery: invalid limit")
+	// ErrInvalidOffset is returned when the offset is negative.
+	ErrInvalidOffset = errors.New("sqlquery: invalid offset")
+	// ErrInvalidAggregate is returned when an aggregate function is not supported or has no column.
+	ErrInvalidAggregate = errors.New("sqlquery: invalid aggregate")
+	// ErrMissingOrderBy is returned when a query that requires an ORDER BY clause has no order.
+	ErrMissingOrderBy = errors.New("sqlquery: missing order by")
+	// ErrMissingConflictColumns is returned when an upsert without conflict columns is requested for a flavor that requires them.
+	ErrMissingConflictColumns = errors.New("sqlquery: missing conflict columns")
+)
+
+// ValidateFilters returns an error if any filter key uses an unknown operator.
+// The keys are parsed by the same code of the queries, so the sub-operators like "popcount.gte" are checked too.
+// A key with two dots may be qualified with a table, like "teams.id.in", but a single dot is always
+// an operator, "teams.id" can't be told apart from a typo like "id.ltee".
+func ValidateFilters(filters map[string]interface{}) error {
+	cond := &sqlbuilder.Cond{Args: &sqlbuilder.Args{}}
+	for _, key := range sortedKeys(filters) {
+		var config filterConfig
+		if strings.Count(key, ".") > 1 {
+			// Any qualifier enables the qualified keys parsing, the column is not rendered.
+			config.qualifier = "t"
+		}
+		if _, err := parseFilter(cond, config, key, filters[key]); errors.Is(err, ErrUnknownOperator) {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizedFilter is a filter key split into field and operator, Operator is empty for the equality filter.
+// Virtual reports if the field is one of the virtual columns, filters on generated columns may not use
+// the indexes like the regular columns.
+type NormalizedFilter struct {
+	Key      string
+	Field    string
+	Operator string
+	Value    interface{}
+	Virtual  bool
+}
+
+func normalizeFilters(filters map[string]interface{}, virtualColumns []string) []NormalizedFilter {
+	normalized := make([]NormalizedFilter, 0, len(filters))
+	for _, key := range sortedKeys(filters) {
+		split := strings.SplitN(key, ".", 2)
+		filter := NormalizedFilter{Key: key, Field: split[0], Value: filters[key]}
+		if len(split) > 1 {
+			filter.Operator = split[1]
+		}
+		filter.Virtual = containsString(virtualColumns, filter.Field)
+		normalized = append(normalized, filter)
+	}
+	return normalized
+}
+
+// isUUID reports if value is a UUID in the canonical 8-4-4-4-12 hexadecimal form.
+func isUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isTid reports if value is a PostgreSQL tuple identifier like "(0,1)", the block and offset numbers.
+func isTid(value string) bool {
+	if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+		return false
+	}
+	parts := strings.Split(value[1:len(value)-1], ",")
+	if len(parts) != 2 {
+		return false
+	}
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isSnapshotID reports if value is a PostgreSQL exported snapshot id like "00000003-0000001B-1",
+// hexadecimal numbers separated by dashes.
+func isSnapshotID(value string) bool {
+	parts := strings.Split(value, "-")
+	if len(parts) < 2 {
+		return false
+	}
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(part, 16, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isNil reports if value is nil or a nil pointer.
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	rv := reflect.ValueOf(value)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+func sortedKeys(filters map[string]interface{}) []string {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// quoteTableName prefixes the table name with schema and quotes each part when the name is schema qualified,
+// plain table names are kept as they are.
+func quoteTableName(flavor Flavor, schema, tableName string) string {
+	if schema != "" {
+		tableName = schema + "." + tableName
+	}
+	if !strings.Contains(tableName, ".") {
+		return tableName
+	}
+	parts := strings.Split(tableName, ".")
+	for i := range parts {
+		if !strings.HasPrefix(parts[i], `"`) && !strings.HasPrefix(parts[i], "`") {
+			parts[i] = flavor.builderFlavor().Quote(parts[i])
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// filterConfig holds the option settings that change how filters are parsed.
+type filterConfig struct {
+	flavor         Flavor
+	emptyIn        EmptyInBehavior
+	qualifier      string
+	allowedColumns []string
+	maxInSize      int
+	skipNil        bool
+}
+
+// allowed reports if the filters can use the column, every column is allowed when allowedColumns is empty.
+func (c filterConfig) allowed(column string) bool {
+	return len(c.allowedColumns) == 0 || containsString(c.allowedColumns, column)
+}
+
+// column qualifies the filter field with the qualifier table name when it is set and the field is not qualified.
+func (c filterConfig) column(field string) string {
+	return qualifyColumn(c.qualifier, field)
+}
+
+// splitKey returns the field and the operator of the filter key, the operator is empty for the equality filter.
+// When the qualifier is set the field may be table qualified, like "teams.id" or "teams.id.in".
+// The "popcount" operator keeps its comparison, like "popcount.gte" for "flags.popcount.gte".
+func (c filterConfig) splitKey(key string) (string, string) {
+	// The key is sliced instead of split, it is parsed for every filter of every query.
+	n := strings.IndexByte(key, '.')
+	if n < 0 {
+		return key, ""
+	}
+	compare := firstPart(key[n+1:])
+	if c.qualifier != "" && !containsString(Operators, compare) {
+		m := strings.IndexByte(key[n+1:], '.')
+		if m < 0 {
+			return key, ""
+		}
+		n += m + 1
+		compare = firstPart(key[n+1:])
+	}
+	if rest := key[n+1:]; compare == "popcount" && len(rest) > len(compare)+1 {
+		return key[:n], rest[:len(compare)+1+len(firstPart(rest[len(compare)+1:]))]
+	}
+	return key[:n], compare
+}
+
+// firstPart returns value up to the first dot.
+func firstPart(value string) string {
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		return value[:i]
+	}
+	return value
+}
+
+// emptyExpr returns the WHERE expression for a filter with an empty set of values.
+func (c filterConfig) emptyExpr() string {
+	if c.emptyIn == EmptyInMatchNothing {
+		return "1 = 0"
+	}
+	return ""
+}
+
+// likeExpr returns "field LIKE pattern" with the ESCAPE clause required by SQLiteFlavor,
+// the pattern must be escaped with EscapeLike.
+func likeExpr(cond *sqlbuilder.Cond, flavor Flavor, field, pattern string) string {
+	expr := cond.Like(field, pattern)
+	if flavor == SQLiteFlavor {
+		expr += ` ESCAPE '\'`
+	}
+	return expr
+}
+
+// parseFilter returns the WHERE expression for the filter, an empty string means that the filter is ignored.
+func parseFilter(cond *sqlbuilder.Cond, config filterConfig, key string, value interface{}) (string, error) {
+	if config.skipNil && isNil(value) {
+		return "", nil
+	}
+	field, compare := config.splitKey(key)
+	if !config.allowed(field) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidColumn, key)
+	}
+	parsedKey := config.column(field)
+	if compare == "" {
 		switch value.(type) {
 		case nil:
-			sb.Where(sb.IsNull(key))
+			return cond.IsNull(parsedKey), nil
 		default:
-			sb.Where(sb.Equal(key, value))
-		}
-	}
-}
-
-func parseUpdateFilter(ub *sqlbuilder.UpdateBuilder, key string, value interface{}) {
-	if strings.Contains(key, ".") {
-		split := strings.Split(key, ".")
-		parsedKey := split[0]
-		compare := split[1]
-		switch compare {
-		case "in":
-			valueStr, ok := value.(string)
-			if ok {
-				values := parseIn(valueStr)
-				ub.Where(ub.In(parsedKey, values...))
-			}
-		case "notin":
-			valueStr, ok := value.(string)
-			if ok {
-				values := parseIn(valueStr)
-				ub.Where(ub.NotIn(parsedKey, values...))
-			}
-		case "not":
-			ub.Where(ub.NotEqual(parsedKey, value))
-		case "gt":
-			ub.Where(ub.GreaterThan(parsedKey, value))
-		case "gte":
-			ub.Where(ub.GreaterEqualThan(parsedKey, value))
-		case "lt":
-			ub.Where(ub.LessThan(parsedKey, value))
-		case "lte":
-			ub.Where(ub.LessEqualThan(parsedKey, value))
-		case "like":
-			ub.Where(ub.Like(parsedKey, value))
-		case "null":
-			valueBool, ok := value.(bool)
-			if ok {
-				if valueBool {
-					ub.Where(ub.IsNull(key))
-				} else {
-					ub.Where(ub.IsNotNull(key))
+			return cond.Equal(parsedKey, value), nil
+		}
+	}
+	switch compare {
+	case "in", "notin":
+		values, err := parseInValues(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %q: %v", ErrInvalidValue, key, err)
+		}
+		if values == nil {
+			return "", nil
+		}
+		if config.maxInSize > 0 && len(values) > config.maxInSize {
+			return "", fmt.Errorf("%w: %q: %d values exceed the maximum of %d", ErrInvalidValue, key, len(values), config.maxInSize)
+		}
+		if len(values) == 0 {
+			if compare == "in" {
+				return config.emptyExpr(), nil
+			}
+			return "", nil
+		}
+		if compare == "in" {
+			return cond.In(parsedKey, values...), nil
+		}
+		return cond.NotIn(parsedKey, values...), nil
+	case "not":
+		return cond.NotEqual(parsedKey, value), nil
+	case "gt":
+		return cond.GreaterThan(parsedKey, value), nil
+	case "gte":
+		return cond.GreaterEqualThan(parsedKey, value), nil
+	case "lt":
+		return cond.LessThan(parsedKey, value), nil
+	case "lte":
+		return cond.LessEqualThan(parsedKey, value), nil
+	case "like":
+		return cond.Like(parsedKey, value), nil
+	case "null":
+		// The unqualified filter keeps the whole key, like "id.null IS NULL", as it always did,
+		// the qualified one uses the column, like "p.deleted_at IS NULL".
+		column := key
+		if config.qualifier != "" {
+			column = parsedKey
+		}
+		valueBool, ok := value.(bool)
+		if ok {
+			if valueBool {
+				return cond.IsNull(column), nil
+			}
+			return cond.IsNotNull(column), nil
+		}
+	case "blank":
+		valueBool, ok := value.(bool)
+		if ok {
+			if valueBool {
+				return cond.Or(cond.IsNull(parsedKey), sqlbuilder.Escape(parsedKey)+" = ''"), nil
+			}
+			return cond.And(cond.IsNotNull(parsedKey), sqlbuilder.Escape(parsedKey)+" <> ''"), nil
+		}
+	case "startswith", "endswith", "contains":
+		valueStr, ok := value.(string)
+		if ok {
+			pattern := EscapeLike(valueStr)
+			switch compare {
+			case "startswith":
+				pattern += "%"
+			case "endswith":
+				pattern = "%" + pattern
+			default:
+				pattern = "%" + pattern + "%"
+			}
+			return likeExpr(cond, config.flavor, parsedKey, pattern), nil
+		}
+	case "approx":
+		approx, ok := value.(Approx)
+		if ok {
+			return fmt.Sprintf("ABS(%s - %s) < %s", sqlbuilder.Escape(parsedKey), cond.Var(approx.Value), cond.Var(approx.Epsilon)), nil
+		}
+	case "startswithany":
+		prefixes, ok := value.([]string)
+		if ok {
+			if len(prefixes) == 0 {
+				return config.emptyExpr(), nil
+			}
+			exprs := make([]string, len(prefixes))
+			for i := range prefixes {
+				exprs[i] = likeExpr(cond, config.flavor, parsedKey, EscapeLike(prefixes[i])+"%")
+			}
+			return cond.Or(exprs...), nil
+		}
+	case "likeany":
+		patterns, ok := value.([]string)
+		if ok {
+			if len(patterns) == 0 {
+				return config.emptyExpr(), nil
+			}
+			if config.flavor == PostgreSQLFlavor {
+				vars := make([]string, len(patterns))
+				for i := range patterns {
+					vars[i] = cond.Var(patterns[i])
 				}
+				return fmt.Sprintf("%s LIKE ANY(ARRAY[%s])", sqlbuilder.Escape(parsedKey), strings.Join(vars, ", ")), nil
+			}
+			exprs := make([]string, len(patterns))
+			for i := range patterns {
+				exprs[i] = likeExpr(cond, config.flavor, parsedKey, patterns[i])
 			}
+			return cond.Or(exprs...), nil
 		}
-	} else {
-		switch value.(type) {
-		case nil:
-			ub.Where(ub.IsNull(key))
+	case "uuid":
+		valueStr, ok := value.(string)
+		if ok {
+			if !isUUID(valueStr) {
+				return "", fmt.Errorf("%w: %q: malformed uuid %q", ErrInvalidValue, key, valueStr)
+			}
+			return cond.Equal(parsedKey, valueStr), nil
+		}
+	case "arraycontains", "arraycontainedby", "arrayoverlap":
+		if config.flavor != PostgreSQLFlavor {
+			return "", fmt.Errorf("%w: %q", ErrUnsupportedOperator, key)
+		}
+		var values []string
+		switch v := value.(type) {
+		case string:
+			values = strings.Split(v, ",")
+		case []string:
+			values = v
+		default:
+			return "", nil
+		}
+		return fmt.Sprintf("%s %s %s", sqlbuilder.Escape(parsedKey), arrayOperators[compare], cond.Var(arrayLiteral(values))), nil
+	case "findinset":
+		if config.flavor != MySQLFlavor && config.flavor != MariaDBFlavor {
+			return "", fmt.Errorf("%w: %q", ErrUnsupportedOperator, key)
+		}
+		return fmt.Sprintf("FIND_IN_SET(%s, %s) > 0", cond.Var(value), sqlbuilder.Escape(parsedKey)), nil
+	case "jsonkey":
+		if config.flavor != MySQLFlavor && config.flavor != MariaDBFlavor {
+			return "", fmt.Errorf("%w: %q", ErrUnsupportedOperator, key)
+		}
+		// The JSON path is bound as an arg, MySQL treats a backslash in a string literal as an escape character.
+		switch v := value.(type) {
+		case string:
+			return fmt.Sprintf("JSON_CONTAINS_PATH(%s, 'one', %s)", sqlbuilder.Escape(parsedKey), cond.Var("$."+v)), nil
+		case JSONKey:
+			field := fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, %s))", sqlbuilder.Escape(parsedKey), cond.Var("$."+v.Key))
+			return fmt.Sprintf("%s = %s", field, cond.Var(v.Value)), nil
+		}
+	case "tid":
+		if config.flavor != PostgreSQLFlavor {
+			return "", fmt.Errorf("%w: %q", ErrUnsupportedOperator, key)
+		}
+		valueStr, ok := value.(string)
+		if ok {
+			if !isTid(valueStr) {
+				return "", fmt.Errorf("%w: %q: malformed tid %q", ErrInvalidValue, key, valueStr)
+			}
+			return cond.Equal(parsedKey, valueStr), nil
+		}
+	case "distinctfrom":
+		return distinctFromExpr(cond, config.flavor, parsedKey, value), nil
+	case "popcount", "popcount.not", "popcount.gt", "popcount.gte", "popcount.lt", "popcount.lte":
+		var bitCount string
+		switch config.flavor {
+		case MySQLFlavor, MariaDBFlavor:
+			bitCount = fmt.Sprintf("BIT_COUNT(%s)", parsedKey)
+		case PostgreSQLFlavor:
+			bitCount = fmt.Sprintf("bit_count(%s)", parsedKey)
 		default:
-			ub.Where(ub.Equal(key, value))
-		}
-	}
-}
-
-func parseDeleteFilter(db *sqlbuilder.DeleteBuilder, key string, value interface{}) {
-	if strings.Contains(key, ".") {
-		split := strings.Split(key, ".")
-		parsedKey := split[0]
-		compare := split[1]
-		switch compare {
-		case "in":
-			valueStr, ok := value.(string)
-			if ok {
-				values := parseIn(valueStr)
-				db.Where(db.In(parsedKey, values...))
-			}
-		case "notin":
-			valueStr, ok := value.(string)
-			if ok {
-				values := parseIn(valueStr)
-				db.Where(db.NotIn(parsedKey, values...))
-			}
-		case "not":
-			db.Where(db.NotEqual(parsedKey, value))
-		case "gt":
-			db.Where(db.GreaterThan(parsedKey, value))
-		case "gte":
-			db.Where(db.GreaterEqualThan(parsedKey, value))
-		case "lt":
-			db.Where(db.LessThan(parsedKey, value))
-		case "lte":
-			db.Where(db.LessEqualThan(parsedKey, value))
-		case "like":
-			db.Where(db.Like(parsedKey, value))
-		case "null":
-			valueBool, ok := value.(bool)
-			if ok {
-				if valueBool {
-					db.Where(db.IsNull(key))
-				} else {
-					db.Where(db.IsNotNull(key))
+			return "", fmt.Errorf("%w: %q", ErrUnsupportedOperator, key)
+		}
+		expr, _ := comparisonExpr(cond, bitCount, strings.TrimPrefix(strings.TrimPrefix(compare, "popcount"), "."), value)
+		return expr, nil
+	case "notilike":
+		if config.flavor == PostgreSQLFlavor {
+			return fmt.Sprintf("%s NOT ILIKE %s", sqlbuilder.Escape(parsedKey), cond.Var(value)), nil
+		}
+		return fmt.Sprintf("LOWER(%s) NOT LIKE LOWER(%s)", sqlbuilder.Escape(parsedKey), cond.Var(value)), nil
+	case "similarto":
+		if config.flavor != PostgreSQLFlavor {
+			return "", fmt.Errorf("%w: %q", ErrUnsupportedOperator, key)
+		}
+		return fmt.Sprintf("%s SIMILAR TO %s", sqlbuilder.Escape(parsedKey), cond.Var(value)), nil
+	case "notdistinctfrom":
+		return notDistinctFromExpr(cond, config.flavor, parsedKey, value), nil
+	case "ieq":
+		return fmt.Sprintf("LOWER(%s) = LOWER(%s)", sqlbuilder.Escape(parsedKey), cond.Var(value)), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownOperator, key)
+	}
+	return "", nil
+}
+
+func parseSelectFilter(sb *sqlbuilder.SelectBuilder, config filterConfig, key string, value interface{}) error {
+	expr, err := parseFilter(&sb.Cond, config, key, value)
+	if err != nil {
+		return err
+	}
+	if expr != "" {
+		sb.Where(expr)
+	}
+	return nil
+}
+
+func parseUpdateFilter(ub *sqlbuilder.UpdateBuilder, config filterConfig, key string, value interface{}) error {
+	expr, err := parseFilter(&ub.Cond, config, key, value)
+	if err != nil {
+		return err
+	}
+	if expr != "" {
+		ub.Where(expr)
+	}
+	return nil
+}
+
+func parseDeleteFilter(db *sqlbuilder.DeleteBuilder, config filterConfig, key string, value interface{}) error {
+	expr, err := parseFilter(&db.Cond, config, key, value)
+	if err != nil {
+		return err
+	}
+	if expr != "" {
+		db.Where(expr)
+	}
+	return nil
+}
+
+// predicate is a WHERE expression that can't be expressed with a single filter key, like a group of filters.
+// The columns are the filtered columns, build must add the args to cond and return an empty string to skip the predicate.
+type predicate struct {
+	columns []string
+	build   func(cond *sqlbuilder.Cond, config filterConfig) (string, error)
+}
+
+// disallowedColumn returns the first column that the filters can't use.
+func disallowedColumn(config filterConfig, columns []string) (string, bool) {
+	for _, column := range columns {
+		if !config.allowed(column) {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+// parsePredicates returns the non empty expressions of predicates, the invalid ones are skipped and the first error is returned.
+func parsePredicates(cond *sqlbuilder.Cond, config filterConfig, predicates []predicate) ([]string, error) {
+	var exprs []string
+	var firstErr error
+	for i := range predicates {
+		if column, ok := disallowedColumn(config, predicates[i].columns); ok {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%w: %q", ErrInvalidColumn, column)
+			}
+			continue
+		}
+		expr, err := predicates[i].build(cond, config)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if expr != "" {
+			exprs = append(exprs, expr)
+		}
+	}
+	return exprs, firstErr
+}
+
+// filterGroupPredicate returns a predicate that combines the filters with AND.
+func filterGroupPredicate(filters map[string]interface{}) predicate {
+	columns := make([]string, 0, len(filters))
+	for _, key := range sortedKeys(filters) {
+		columns = append(columns, strings.Split(key, ".")[0])
+	}
+	return predicate{
+		columns: columns,
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			var exprs []string
+			var firstErr error
+			for _, key := range sortedKeys(filters) {
+				expr, err := parseFilter(cond, config, key, filters[key])
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+					continue
 				}
+				if expr != "" {
+					exprs = append(exprs, expr)
+				}
+			}
+			if len(exprs) == 0 {
+				return "", firstErr
 			}
+			return cond.And(exprs...), firstErr
+		},
+	}
+}
+
+// conditionColumns appends the filter columns of the leaves of c to columns.
+func conditionColumns(columns []string, c Condition) []string {
+	if c.Key != "" {
+		return append(columns, strings.Split(c.Key, ".")[0])
+	}
+	for _, child := range c.Children {
+		columns = conditionColumns(columns, child)
+	}
+	return columns
+}
+
+// buildCondition returns the expression of c, a leaf filter or the children combined with AND or OR.
+// The invalid leaves and the empty groups are skipped and the first error is returned.
+func buildCondition(cond *sqlbuilder.Cond, config filterConfig, c Condition) (string, error) {
+	if c.Key != "" {
+		return parseFilter(cond, config, c.Key, c.Value)
+	}
+	var exprs []string
+	var firstErr error
+	for _, child := range c.Children {
+		expr, err := buildCondition(cond, config, child)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if expr != "" {
+			exprs = append(exprs, expr)
 		}
+	}
+	switch {
+	case len(exprs) == 0:
+		return "", firstErr
+	case c.Or:
+		return cond.Or(exprs...), firstErr
+	default:
+		return cond.And(exprs...), firstErr
+	}
+}
+
+// conditionPredicate returns a predicate that builds the boolean filter tree c.
+func conditionPredicate(c Condition) predicate {
+	return predicate{
+		columns: conditionColumns(nil, c),
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			return buildCondition(cond, config, c)
+		},
+	}
+}
+
+// notGroupPredicate returns a predicate that negates the filters combined with AND.
+func notGroupPredicate(filters map[string]interface{}) predicate {
+	group := filterGroupPredicate(filters)
+	return predicate{
+		columns: group.columns,
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			expr, err := group.build(cond, config)
+			if expr == "" {
+				return "", err
+			}
+			return "NOT " + expr, err
+		},
+	}
+}
+
+// distinctFromExpr returns the null safe "field is different from value" expression of the flavor,
+// IS DISTINCT FROM on PostgreSQL, IS NOT on SQLite, that has the same meaning and works before SQLite 3.39,
+// and NOT (field <=> value) on MySQL and MariaDB.
+func distinctFromExpr(cond *sqlbuilder.Cond, flavor Flavor, field string, value interface{}) string {
+	switch flavor {
+	case PostgreSQLFlavor:
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", sqlbuilder.Escape(field), cond.Var(value))
+	case SQLiteFlavor:
+		return fmt.Sprintf("%s IS NOT %s", sqlbuilder.Escape(field), cond.Var(value))
+	default:
+		return fmt.Sprintf("NOT (%s)", notDistinctFromExpr(cond, flavor, field, value))
+	}
+}
+
+// notDistinctFromExpr returns the null safe "field is equal to value" expression of the flavor, the negation
+// of distinctFromExpr, IS NOT DISTINCT FROM on PostgreSQL, IS on SQLite and field <=> value on MySQL and MariaDB.
+func notDistinctFromExpr(cond *sqlbuilder.Cond, flavor Flavor, field string, value interface{}) string {
+	switch flavor {
+	case PostgreSQLFlavor:
+		return fmt.Sprintf("%s IS NOT DISTINCT FROM %s", sqlbuilder.Escape(field), cond.Var(value))
+	case SQLiteFlavor:
+		return fmt.Sprintf("%s IS %s", sqlbuilder.Escape(field), cond.Var(value))
+	default:
+		return fmt.Sprintf("%s <=> %s", sqlbuilder.Escape(field), cond.Var(value))
+	}
+}
+
+// jsonExtractExpr returns the expression that extracts the dot separated path of the JSON column.
+// The path is bound as an arg, a text array on PostgreSQL like data#>>$1::text[] with {shipping,status} and
+// a JSON path on MySQL, MariaDB and SQLite, and the returned expression is escaped to be used as is.
+func jsonExtractExpr(cond *sqlbuilder.Cond, flavor Flavor, column, path string) string {
+	column = sqlbuilder.Escape(column)
+	switch flavor {
+	case PostgreSQLFlavor:
+		return fmt.Sprintf("%s#>>%s::text[]", column, cond.Var(arrayLiteral(strings.Split(path, "."))))
+	case SQLiteFlavor:
+		return fmt.Sprintf("json_extract(%s, %s)", column, cond.Var("$."+path))
+	default:
+		return fmt.Sprintf("JSON_EXTRACT(%s, %s)", column, cond.Var("$."+path))
+	}
+}
+
+// jsonPredicate returns a predicate that compares the path of the JSON column with value, a nil value matches NULL.
+func jsonPredicate(column, path string, value interface{}) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			expr := jsonExtractExpr(cond, config.flavor, config.column(column), path)
+			if value == nil {
+				return expr + " IS NULL", nil
+			}
+			return fmt.Sprintf("%s = %s", expr, cond.Var(value)), nil
+		},
+	}
+}
+
+// inSubqueryPredicate returns a predicate that matches the column with the rows selected by sub from subTable.
+func inSubqueryPredicate(column string, sub *FindOptions, subTable string) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			sb, err := findBuilder(subTable, sub)
+			if err != nil {
+				return "", err
+			}
+			return cond.In(config.column(column), sb), nil
+		},
+	}
+}
+
+// existsPredicate returns a predicate that matches the rows with a subTable row that satisfies correlationExpr
+// and the subFilters, or the rows without one when negate is set.
+func existsPredicate(subTable, correlationExpr string, subFilters map[string]interface{}, negate bool) predicate {
+	return predicate{
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			sb := sqlbuilder.NewSelectBuilder()
+			sb.SetFlavor(config.flavor.builderFlavor())
+			sb.Select("1").From(quoteTableName(config.flavor, "", subTable))
+			sb.Where(sqlbuilder.Escape(correlationExpr))
+			subConfig := filterConfig{flavor: config.flavor, emptyIn: config.emptyIn, maxInSize: config.maxInSize}
+			if err := parseSelectFilters(sb, subConfig, subFilters, nil); err != nil {
+				return "", err
+			}
+			if negate {
+				return fmt.Sprintf("NOT EXISTS (%s)", cond.Var(sb)), nil
+			}
+			return fmt.Sprintf("EXISTS (%s)", cond.Var(sb)), nil
+		},
+	}
+}
+
+// castTypes are the numeric types accepted by CAST for each flavor.
+var castTypes = map[Flavor][]string{
+	MySQLFlavor:      {"SIGNED", "UNSIGNED", "DECIMAL", "DOUBLE"},
+	MariaDBFlavor:    {"SIGNED", "UNSIGNED", "DECIMAL", "DOUBLE"},
+	PostgreSQLFlavor: {"SMALLINT", "INTEGER", "BIGINT", "NUMERIC", "DECIMAL", "REAL", "DOUBLE PRECISION"},
+	SQLiteFlavor:     {"INTEGER", "REAL", "NUMERIC"},
+}
+
+// comparisonExpr returns the comparison of field with value for the "" (equal), "not", "gt", "gte", "lt" and "lte" operators.
+func comparisonExpr(cond *sqlbuilder.Cond, field, op string, value interface{}) (string, bool) {
+	switch op {
+	case "":
+		return cond.Equal(field, value), true
+	case "not":
+		return cond.NotEqual(field, value), true
+	case "gt":
+		return cond.GreaterThan(field, value), true
+	case "gte":
+		return cond.GreaterEqualThan(field, value), true
+	case "lt":
+		return cond.LessThan(field, value), true
+	case "lte":
+		return cond.LessEqualThan(field, value), true
+	}
+	return "", false
+}
+
+// numericTextPredicate returns a predicate that compares the text column casted to castType with value.
+func numericTextPredicate(column, op string, value interface{}, castType string) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			castType := strings.ToUpper(castType)
+			if !containsString(castTypes[config.flavor], castType) {
+				return "", fmt.Errorf("%w: %q: unsupported cast type %q", ErrInvalidValue, column, castType)
+			}
+			expr, ok := comparisonExpr(cond, fmt.Sprintf("CAST(%s AS %s)", config.column(column), castType), op, value)
+			if !ok {
+				return "", fmt.Errorf("%w: %q", ErrUnknownOperator, column+"."+op)
+			}
+			return expr, nil
+		},
+	}
+}
+
+// timeInIntervalPredicate returns a predicate that matches the rows whose half-open interval [startCol, endCol) contains value,
+// like ($1 >= start_at AND $1 < end_at). On PostgreSQL value is bound once and both comparisons share its placeholder,
+// the "?" flavors have no way to reference an arg twice, so value is bound once per comparison there.
+func timeInIntervalPredicate(value interface{}, startCol, endCol string) predicate {
+	return predicate{
+		columns: []string{startCol, endCol},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			first, second := cond.Var(value), cond.Var(value)
+			if config.flavor == PostgreSQLFlavor {
+				index := new(int)
+				first = cond.Var(sharedArg{value: value, index: index, first: true})
+				second = cond.Var(sharedArg{index: index})
+			}
+			return cond.And(
+				fmt.Sprintf("%s >= %s", first, sqlbuilder.Escape(config.column(startCol))),
+				fmt.Sprintf("%s < %s", second, sqlbuilder.Escape(config.column(endCol))),
+			), nil
+		},
+	}
+}
+
+// sharedArg is a PostgreSQL arg that can be referenced more than once, the first reference binds value and
+// records its position in index, the next ones reuse the same "$n" placeholder without binding value again.
+type sharedArg struct {
+	value interface{}
+	index *int
+	first bool
+}
+
+// Build implements sqlbuilder.Builder.
+func (a sharedArg) Build() (string, []interface{}) {
+	return a.BuildWithFlavor(sqlbuilder.PostgreSQL)
+}
+
+// BuildWithFlavor implements sqlbuilder.Builder.
+func (a sharedArg) BuildWithFlavor(flavor sqlbuilder.Flavor, initialArg ...interface{}) (string, []interface{}) {
+	if a.first {
+		initialArg = append(initialArg, a.value)
+		*a.index = len(initialArg)
+	}
+	return fmt.Sprintf("$%d", *a.index), initialArg
+}
+
+// roundedPredicate returns a predicate that compares the column rounded to decimals with value.
+func roundedPredicate(column string, decimals int, op string, value interface{}) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			expr, ok := comparisonExpr(cond, fmt.Sprintf("ROUND(%s, %d)", config.column(column), decimals), op, value)
+			if !ok {
+				return "", fmt.Errorf("%w: %q", ErrUnknownOperator, column+"."+op)
+			}
+			return expr, nil
+		},
+	}
+}
+
+// searchPredicate returns a predicate that matches the rows with any of the columns containing value.
+func searchPredicate(value string, columns []string) predicate {
+	return predicate{
+		columns: columns,
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			if value == "" || len(columns) == 0 {
+				return "", nil
+			}
+			pattern := "%" + EscapeLike(value) + "%"
+			exprs := make([]string, len(columns))
+			for i := range columns {
+				exprs[i] = likeExpr(cond, config.flavor, config.column(columns[i]), pattern)
+			}
+			return cond.Or(exprs...), nil
+		},
+	}
+}
+
+// textLikePredicate returns a predicate that casts the column to text with the flavor syntax and matches it
+// with a LIKE on the escaped value, ignoring an empty value like searchPredicate.
+func textLikePredicate(column, value string) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			if value == "" {
+				return "", nil
+			}
+			field := config.column(column)
+			switch config.flavor {
+			case PostgreSQLFlavor:
+				field += "::text"
+			case MySQLFlavor, MariaDBFlavor:
+				field = fmt.Sprintf("CAST(%s AS CHAR)", field)
+			default:
+				field = fmt.Sprintf("CAST(%s AS TEXT)", field)
+			}
+			return likeExpr(cond, config.flavor, field, "%"+EscapeLike(value)+"%"), nil
+		},
+	}
+}
+
+// columnComparisonPredicate returns a predicate that compares the left column with the right column.
+func columnComparisonPredicate(left, op, right string) predicate {
+	return predicate{
+		columns: []string{left, right},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			if !containsString([]string{"=", "<>", "!=", "<", "<=", ">", ">="}, op) {
+				return "", fmt.Errorf("%w: %q", ErrUnknownOperator, op)
+			}
+			for _, column := range []string{left, right} {
+				if !isColumnName(column) {
+					return "", fmt.Errorf("%w: %q", ErrInvalidColumn, column)
+				}
+			}
+			return fmt.Sprintf("%s %s %s", config.column(left), op, config.column(right)), nil
+		},
+	}
+}
+
+// nonASCIIPredicate returns a predicate that matches the rows with any non-ASCII character in the column.
+func nonASCIIPredicate(column string) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			field := config.column(column)
+			switch config.flavor {
+			case PostgreSQLFlavor:
+				return sqlbuilder.Escape(fmt.Sprintf("%s !~ '^[[:ascii:]]*$'", field)), nil
+			case MySQLFlavor, MariaDBFlavor:
+				return sqlbuilder.Escape(fmt.Sprintf("%s <> CONVERT(%s USING ascii)", field, field)), nil
+			default:
+				return "", fmt.Errorf("%w: %q", ErrUnsupportedFlavor, column)
+			}
+		},
+	}
+}
+
+// dateEqualsPredicate returns a predicate that matches the rows whose column is in the day of date,
+// the date is bound with DateLayout.
+func dateEqualsPredicate(column string, date time.Time) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			field := fmt.Sprintf("DATE(%s)", config.column(column))
+			if config.flavor == PostgreSQLFlavor {
+				field = config.column(column) + "::date"
+			}
+			return cond.Equal(field, date.Format(DateLayout)), nil
+		},
+	}
+}
+
+// epochPredicate returns a predicate that compares the unix epoch seconds column with t converted to epoch seconds.
+func epochPredicate(column, op string, t time.Time) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			expr, ok := comparisonExpr(cond, config.column(column), op, t.Unix())
+			if !ok {
+				return "", fmt.Errorf("%w: %q", ErrUnknownOperator, column+"."+op)
+			}
+			return expr, nil
+		},
+	}
+}
+
+// arrayIndexPredicate returns a predicate that compares the element of the PostgreSQL array column at index with value.
+func arrayIndexPredicate(column string, index int, op string, value interface{}) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			if config.flavor != PostgreSQLFlavor {
+				return "", fmt.Errorf("%w: %q", ErrUnsupportedFlavor, column)
+			}
+			if index < 1 {
+				return "", fmt.Errorf("%w: %q: invalid array index %d", ErrInvalidValue, column, index)
+			}
+			expr, ok := comparisonExpr(cond, fmt.Sprintf("%s[%d]", config.column(column), index), op, value)
+			if !ok {
+				return "", fmt.Errorf("%w: %q", ErrUnknownOperator, column+"."+op)
+			}
+			return expr, nil
+		},
+	}
+}
+
+// extremePredicate returns a predicate that compares the greatest, or the least when least is set, of the columns with value.
+func extremePredicate(columns []string, least bool, op string, value interface{}) predicate {
+	return predicate{
+		columns: columns,
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			if len(columns) == 0 {
+				return "", fmt.Errorf("%w: %q", ErrInvalidColumn, "")
+			}
+			qualified := make([]string, len(columns))
+			for i, column := range columns {
+				if !isColumnName(column) {
+					return "", fmt.Errorf("%w: %q", ErrInvalidColumn, column)
+				}
+				qualified[i] = config.column(column)
+			}
+			fn := "GREATEST"
+			switch {
+			case least && config.flavor == SQLiteFlavor:
+				fn = "MIN"
+			case least:
+				fn = "LEAST"
+			case config.flavor == SQLiteFlavor:
+				fn = "MAX"
+			}
+			expr, ok := comparisonExpr(cond, fmt.Sprintf("%s(%s)", fn, strings.Join(qualified, ", ")), op, value)
+			if !ok {
+				return "", fmt.Errorf("%w: %q", ErrUnknownOperator, op)
+			}
+			return expr, nil
+		},
+	}
+}
+
+// rangeListPredicate returns a predicate that matches the column between the bounds of any of the ranges.
+func rangeListPredicate(column string, ranges [][2]interface{}) predicate {
+	return predicate{
+		columns: []string{column},
+		build: func(cond *sqlbuilder.Cond, config filterConfig) (string, error) {
+			if len(ranges) == 0 {
+				return config.emptyExpr(), nil
+			}
+			exprs := make([]string, len(ranges))
+			for i := range ranges {
+				exprs[i] = cond.Between(config.column(column), ranges[i][0], ranges[i][1])
+			}
+			return cond.Or(exprs...), nil
+		},
+	}
+}
+
+// parseSelectFilters applies all filters in key order followed by the predicates,
+// the invalid ones are skipped and the first error is returned.
+func parseSelectFilters(sb *sqlbuilder.SelectBuilder, config filterConfig, filters map[string]interface{}, predicates []predicate) error {
+	var firstErr error
+	for _, key := range sortedKeys(filters) {
+		if err := parseSelectFilter(sb, config, key, filters[key]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	exprs, err := parsePredicates(&sb.Cond, config, predicates)
+	if len(exprs) > 0 {
+		sb.Where(exprs...)
+	}
+	return firstError(firstErr, err)
+}
+
+// parseUpdateFilters applies all filters in key order followed by the predicates,
+// the invalid ones are skipped and the first error is returned.
+func parseUpdateFilters(ub *sqlbuilder.UpdateBuilder, config filterConfig, filters map[string]interface{}, predicates []predicate) error {
+	var firstErr error
+	for _, key := range sortedKeys(filters) {
+		if err := parseUpdateFilter(ub, config, key, filters[key]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	exprs, err := parsePredicates(&ub.Cond, config, predicates)
+	if len(exprs) > 0 {
+		ub.Where(exprs...)
+	}
+	return firstError(firstErr, err)
+}
+
+// parseDeleteFilters applies all filters in key order followed by the predicates,
+// the invalid ones are skipped and the first error is returned.
+func parseDeleteFilters(db *sqlbuilder.DeleteBuilder, config filterConfig, filters map[string]interface{}, predicates []predicate) error {
+	var firstErr error
+	for _, key := range sortedKeys(filters) {
+		if err := parseDeleteFilter(db, config, key, filters[key]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	exprs, err := parsePredicates(&db.Cond, config, predicates)
+	if len(exprs) > 0 {
+		db.Where(exprs...)
+	}
+	return firstError(firstErr, err)
+}
+
+// lockConfig holds the option settings of the row locking clause.
+type lockConfig struct {
+	forUpdate bool
+	forShare  bool
+	mode      string
+	wait      int
+}
+
+// buildSelect returns compiled SELECT string and args with the row locking clause for the flavor.
+// MySQLFlavor and MariaDBFlavor use "LOCK IN SHARE MODE" for shared locks, SQLiteFlavor doesn't support row locks.
+// The lock wait is only rendered for MariaDBFlavor.
+func buildSelect(sb *sqlbuilder.SelectBuilder, flavor Flavor, lock lockConfig) (string, []interface{}) {
+	if flavor == SQLiteFlavor || (!lock.forUpdate && !lock.forShare) {
+		return sb.Build()
+	}
+	var suffix []string
+	if flavor == MariaDBFlavor && lock.wait > 0 {
+		suffix = append(suffix, fmt.Sprintf("WAIT %d", lock.wait))
+	}
+	if lock.mode != "" {
+		suffix = append(suffix, lock.mode)
+	}
+	// MySQL 8 only accepts NOWAIT and SKIP LOCKED after FOR SHARE, MariaDB doesn't support FOR SHARE.
+	if lock.forShare && (flavor == MariaDBFlavor || (flavor == MySQLFlavor && lock.mode == "")) {
+		// go-sqlbuilder only renders FOR SHARE, the clause is appended to the compiled sql.
+		sqlQuery, args := sb.Build()
+		return strings.Join(append([]string{sqlQuery, "LOCK IN SHARE MODE"}, suffix...), " "), args
+	}
+	if lock.forShare {
+		sb.ForShare()
 	} else {
-		switch value.(type) {
-		case nil:
-			db.Where(db.IsNull(key))
-		default:
-			db.Where(db.Equal(key, value))
+		sb.ForUpdate()
+	}
+	for i := range suffix {
+		sb.SQL(suffix[i])
+	}
+	return sb.Build()
+}
+
+// parseLimitOffset clamps negative values to zero and returns -1 to omit the LIMIT clause when limit is zero,
+// the OFFSET clause is also omitted when there is no LIMIT and offset is zero.
+func parseLimitOffset(limit, offset int) (int, int, error) {
+	var err error
+	if limit < 0 {
+		err = fmt.Errorf("%w: %d", ErrInvalidLimit, limit)
+		limit = 0
+	}
+	if offset < 0 {
+		err = firstError(err, fmt.Errorf("%w: %d", ErrInvalidOffset, offset))
+		offset = 0
+	}
+	if limit == 0 {
+		limit = -1
+		if offset == 0 {
+			offset = -1
+		}
+	}
+	return limit, offset, err
+}
+
+// firstNonEmpty returns the first non empty value.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
 		}
 	}
+	return ""
+}
+
+// firstError returns the first non nil error.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectFrom sets the fields and the table of sb, the table is aliased and the bare fields are qualified when alias is set.
+func selectFrom(sb *sqlbuilder.SelectBuilder, flavor Flavor, schema, tableName, alias string, fields []string, joins []Join) {
+	table := quoteTableName(flavor, schema, tableName)
+	if alias != "" {
+		table = sb.As(table, alias)
+	}
+	sb.Select(qualifyFields(alias, fields)...).From(table)
+	for _, join := range joins {
+		sb.Join(quoteTableName(flavor, "", join.Table), join.On)
+	}
+}
+
+// isTypeName reports if value is a type name like "bigint", "double precision", "numeric(10,2)" or "text[]".
+func isTypeName(value string) bool {
+	value = strings.TrimSuffix(value, "[]")
+	if i := strings.IndexByte(value, '('); i >= 0 {
+		if !strings.HasSuffix(value, ")") {
+			return false
+		}
+		for _, size := range strings.Split(value[i+1:len(value)-1], ",") {
+			if _, err := strconv.ParseUint(size, 10, 32); err != nil {
+				return false
+			}
+		}
+		value = value[:i]
+	}
+	for _, word := range strings.Split(value, " ") {
+		if !isColumnName(word) || strings.Contains(word, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// joinValues adds the INNER JOIN of each ValuesJoin on its first column equal to the table column,
+// the invalid joins are skipped and the first error is returned.
+func joinValues(sb *sqlbuilder.SelectBuilder, flavor Flavor, table string, joins []ValuesJoin) error {
+	var firstErr error
+	for _, join := range joins {
+		expr, err := valuesJoinExpr(sb, flavor, join)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		column := strings.SplitN(join.Columns[0], "::", 2)[0]
+		sb.Join(expr, fmt.Sprintf("%s = %s.%s", qualifyColumn(table, column), join.Alias, column))
+	}
+	return firstErr
+}
+
+// valuesJoinExpr returns the aliased VALUES list of join with the row values added to sb.
+func valuesJoinExpr(sb *sqlbuilder.SelectBuilder, flavor Flavor, join ValuesJoin) (string, error) {
+	if !isColumnName(join.Alias) || strings.Contains(join.Alias, ".") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidColumn, join.Alias)
+	}
+	if len(join.Columns) == 0 || len(join.Rows) == 0 {
+		return "", fmt.Errorf("%w: %q: empty values join", ErrInvalidValue, join.Alias)
+	}
+	names := make([]string, len(join.Columns))
+	casts := make([]string, len(join.Columns))
+	for i, column := range join.Columns {
+		split := strings.SplitN(column, "::", 2)
+		if !isColumnName(split[0]) || strings.Contains(split[0], ".") || (len(split) == 2 && !isTypeName(split[1])) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidColumn, column)
+		}
+		names[i] = split[0]
+		if len(split) == 2 && flavor == PostgreSQLFlavor {
+			casts[i] = "::" + split[1]
+		}
+	}
+	rows := make([]string, len(join.Rows))
+	for i, row := range join.Rows {
+		if len(row) != len(names) {
+			return "", fmt.Errorf("%w: %q: row %d has %d values, expected %d", ErrInvalidValue, join.Alias, i, len(row), len(names))
+		}
+		vars := make([]string, len(row))
+		for j := range row {
+			vars[j] = sb.Var(row[j])
+			switch {
+			case i == 0 && (flavor == SQLiteFlavor || flavor == MariaDBFlavor):
+				vars[j] += " AS " + names[j]
+			case i == 0:
+				vars[j] += casts[j]
+			}
+		}
+		rows[i] = strings.Join(vars, ", ")
+	}
+	switch flavor {
+	case SQLiteFlavor, MariaDBFlavor:
+		return fmt.Sprintf("(SELECT %s) AS %s", strings.Join(rows, " UNION ALL SELECT "), join.Alias), nil
+	case MySQLFlavor:
+		return fmt.Sprintf("(VALUES ROW(%s)) AS %s (%s)", strings.Join(rows, "), ROW("), join.Alias, strings.Join(names, ", ")), nil
+	default:
+		return fmt.Sprintf("(VALUES (%s)) AS %s (%s)", strings.Join(rows, "), ("), join.Alias, strings.Join(names, ", ")), nil
+	}
+}
+
+func findBuilder(tableName string, options *FindOptions) (*sqlbuilder.SelectBuilder, error) {
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.SetFlavor(options.Flavor.builderFlavor())
+	selectFrom(sb, options.Flavor, options.Schema, tableName, options.TableAlias, options.Fields, options.Joins)
+	joinErr := joinValues(sb, options.Flavor, firstNonEmpty(options.TableAlias, tableName), options.ValuesJoins)
+	config := options.filterConfig()
+	err := firstError(joinErr, parseSelectFilters(sb, config, options.Filters, options.predicates))
+	if options.SoftDeleteColumn != "" && !options.IncludeDeleted {
+		sb.Where(sb.IsNull(config.column(options.SoftDeleteColumn)))
+	}
+	return sb, err
 }
 
 // FindQuery returns compiled SELECT string and args.
+// Filters with unknown operators are ignored, use FindQueryE to get an error instead.
 func FindQuery(tableName string, options *FindOptions) (string, []interface{}) {
+	sb, _ := findBuilder(tableName, options)
+	return buildSelect(sb, options.Flavor, options.lockConfig())
+}
+
+// FindQueryE returns compiled SELECT string and args or an error if any filter is invalid.
+func FindQueryE(tableName string, options *FindOptions) (string, []interface{}, error) {
+	sb, err := findBuilder(tableName, options)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlQuery, args := buildSelect(sb, options.Flavor, options.lockConfig())
+	return sqlQuery, args, nil
+}
+
+func findAllBuilder(tableName string, options *FindAllOptions) (*sqlbuilder.SelectBuilder, error) {
 	sb := sqlbuilder.NewSelectBuilder()
-	sb.SetFlavor(sqlbuilder.Flavor(options.Flavor))
-	sb.Select(options.Fields...).From(tableName)
-	for key, value := range options.Filters {
-		parseSelectFilter(sb, key, value)
+	sb.SetFlavor(options.Flavor.builderFlavor())
+	if cte := options.RecursiveCTE; cte != nil {
+		cteBuilder := sqlbuilder.Build(cte.BaseQuery+" UNION ALL "+cte.RecursiveQuery, cte.Args...)
+		sb.SQL(fmt.Sprintf("WITH RECURSIVE %s AS (%s)", cte.Name, sb.Var(cteBuilder)))
 	}
-	if options.ForUpdate {
-		sb.ForUpdate()
-		if options.ForUpdateMode != "" {
-			sb.SQL(options.ForUpdateMode)
+	limit, offset, limitErr := parseLimitOffset(options.Limit, options.Offset)
+	fields, fieldsErr := options.fields()
+	selectFrom(sb, options.Flavor, options.Schema, tableName, options.TableAlias, fields, options.Joins)
+	joinErr := joinValues(sb, options.Flavor, firstNonEmpty(options.TableAlias, tableName), options.ValuesJoins)
+	useFetch := options.Fetch && (options.Flavor == PostgreSQLFlavor || options.Flavor == MariaDBFlavor)
+	if !useFetch {
+		sb.Limit(limit).Offset(offset)
+	}
+	config := options.filterConfig()
+	filterErr := parseSelectFilters(sb, config, options.Filters, options.predicates)
+	if options.SoftDeleteColumn != "" && !options.IncludeDeleted {
+		sb.Where(sb.IsNull(config.column(options.SoftDeleteColumn)))
+	}
+	randomOrder, randomErr := options.randomOrder(&sb.Cond)
+	if randomOrder != "" {
+		sb.OrderBy(randomOrder)
+	}
+	cursorExpr, cursorErr := options.cursorTokenExpr(&sb.Cond)
+	if cursorExpr != "" {
+		sb.Where(cursorExpr)
+	}
+	orderBy, orderErr := options.orderBy()
+	if orderBy != "" {
+		sb.OrderBy(orderBy)
+	}
+	if useFetch {
+		if offset > 0 || limit >= 0 {
+			sb.SQL(fmt.Sprintf("OFFSET %d ROWS", max(offset, 0)))
+		}
+		if limit >= 0 {
+			sb.SQL(fmt.Sprintf("FETCH NEXT %d ROWS ONLY", limit))
 		}
 	}
-	return sb.Build()
+	return sb, firstError(limitErr, fieldsErr, joinErr, filterErr, cursorErr, randomErr, orderErr)
 }
 
 // FindAllQuery returns compiled SELECT string and args.
+// Filters with unknown operators are ignored and negative Limit and Offset are handled as zero,
+// use FindAllQueryE to get an error instead. A zero Limit omits the LIMIT clause and returns all rows.
 func FindAllQuery(tableName string, options *FindAllOptions) (string, []interface{}) {
+	sb, _ := findAllBuilder(tableName, options)
+	return buildSelect(sb, options.Flavor, options.lockConfig())
+}
+
+// FindAllQueryE returns compiled SELECT string and args or an error if any filter is invalid.
+func FindAllQueryE(tableName string, options *FindAllOptions) (string, []interface{}, error) {
+	sb, err := findAllBuilder(tableName, options)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlQuery, args := buildSelect(sb, options.Flavor, options.lockConfig())
+	return sqlQuery, args, nil
+}
+
+// FindAllQueryInterpolated returns the SELECT string of FindAllQueryE with the args interpolated as literals
+// of the flavor, like SELECT * FROM players WHERE name = 'R10', for logging and EXPLAIN tooling.
+// The interpolated string must not be sent to the database, the args are only escaped and not bound,
+// use FindAllQueryE to run the query.
+func FindAllQueryInterpolated(tableName string, options *FindAllOptions) (string, error) {
+	sqlQuery, args, err := FindAllQueryE(tableName, options)
+	if err != nil {
+		return "", err
+	}
+	return options.Flavor.builderFlavor().Interpolate(sqlQuery, args)
+}
+
+// FindAllPageQuery returns compiled SELECT string and args like FindAllQuery when the page can have rows.
+// When the caller already knows the total number of rows and Offset is not lower than total,
+// a query that selects no rows is returned, so the caller may skip it. It keeps the fields, TableAlias and joins
+// of the page query with the WHERE clause replaced by 1 = 0, only the RecursiveCTE and ValuesJoins have args.
+func FindAllPageQuery(tableName string, options *FindAllOptions, total int) (string, []interface{}) {
+	if options.Offset < total {
+		return FindAllQuery(tableName, options)
+	}
+	emptyOptions := options.unordered()
+	emptyOptions.Filters = nil
+	emptyOptions.predicates = nil
+	emptyOptions.SoftDeleteColumn = ""
+	emptyOptions.Limit = 0
+	emptyOptions.Offset = 0
+	emptyOptions.Fetch = false
+	sb, _ := findAllBuilder(tableName, &emptyOptions)
+	sb.Where("1 = 0")
+	return sb.Build()
+}
+
+// FindAllWithCountQuery returns compiled SELECT string and args of the page like FindAllQuery followed by the compiled
+// SELECT COUNT(*) string and args of all the rows that match the same filters. The count query has no LIMIT, OFFSET,
+// ORDER BY or locking clause. With a SelectPrefix, like DISTINCT, the page query is counted as a subquery,
+// like SELECT COUNT(*) FROM (SELECT DISTINCT team_id FROM players) AS t, to count the rows the pages return.
+func FindAllWithCountQuery(tableName string, options *FindAllOptions) (string, []interface{}, string, []interface{}) {
+	pageSQL, pageArgs := FindAllQuery(tableName, options)
+	countOptions := options.unordered()
+	countOptions.Limit = 0
+	countOptions.Offset = 0
+	if len(options.SelectPrefix) > 0 {
+		inner, _ := findAllBuilder(tableName, &countOptions)
+		sb := sqlbuilder.NewSelectBuilder()
+		sb.SetFlavor(options.Flavor.builderFlavor())
+		sb.Select("COUNT(*)").From(sb.BuilderAs(inner, "t"))
+		countSQL, countArgs := sb.Build()
+		return pageSQL, pageArgs, countSQL, countArgs
+	}
+	countOptions.Fields = []string{"COUNT(*)"}
+	sb, _ := findAllBuilder(tableName, &countOptions)
+	countSQL, countArgs := sb.Build()
+	return pageSQL, pageArgs, countSQL, countArgs
+}
+
+// JSONAggQuery returns compiled SELECT string and args that returns the rows selected by options as a single
+// JSON array, like SELECT json_agg(t) FROM (SELECT ...) AS t. The array keeps the order of OrderBy and
+// it is NULL when no rows are selected. Only PostgreSQLFlavor is supported, ErrUnsupportedFlavor is returned otherwise.
+func JSONAggQuery(tableName string, options *FindAllOptions) (string, []interface{}, error) {
+	if options.Flavor != PostgreSQLFlavor {
+		return "", nil, ErrUnsupportedFlavor
+	}
+	inner, err := findAllBuilder(tableName, options)
+	if err != nil {
+		return "", nil, err
+	}
 	sb := sqlbuilder.NewSelectBuilder()
-	sb.SetFlavor(sqlbuilder.Flavor(options.Flavor))
-	sb.Select(options.Fields...).From(tableName).Limit(options.Limit).Offset(options.Offset)
-	for key, value := range options.Filters {
-		parseSelectFilter(sb, key, value)
+	sb.SetFlavor(options.Flavor.builderFlavor())
+	sb.Select("json_agg(t)").From(sb.BuilderAs(inner, "t"))
+	sqlQuery, args := sb.Build()
+	return sqlQuery, args, nil
+}
+
+// Aggregate describes the aggregate function of AggregateQuery, like COUNT(DISTINCT user_id).
+// Func is one of "COUNT", "SUM", "AVG", "MIN" and "MAX", an empty Column or "*" is only allowed with COUNT.
+type Aggregate struct {
+	Func     string
+	Column   string
+	Distinct bool
+}
+
+// expr returns the aggregate expression or an error if the aggregate is invalid.
+func (a Aggregate) expr() (string, error) {
+	fn := strings.ToUpper(a.Func)
+	if !containsString([]string{"COUNT", "SUM", "AVG", "MIN", "MAX"}, fn) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAggregate, a.Func)
 	}
-	if options.OrderBy != "" {
-		sb.OrderBy(options.OrderBy)
+	column := a.Column
+	if column == "" {
+		column = "*"
 	}
-	if options.ForUpdate {
-		sb.ForUpdate()
-		if options.ForUpdateMode != "" {
-			sb.SQL(options.ForUpdateMode)
+	if column == "*" && (fn != "COUNT" || a.Distinct) {
+		return "", fmt.Errorf("%w: %s requires a column", ErrInvalidAggregate, fn)
+	}
+	if column != "*" && !isColumnName(column) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidColumn, column)
+	}
+	if a.Distinct {
+		return fmt.Sprintf("%s(DISTINCT %s)", fn, column), nil
+	}
+	return fmt.Sprintf("%s(%s)", fn, column), nil
+}
+
+// AggregateQuery returns compiled SELECT string and args of the aggregate function applied to the filtered rows.
+// Fields, SelectPrefix, Limit, Offset and OrderBy are ignored, the query returns a single row with a single column.
+// The column must be one of AllowedColumns when they are set.
+func AggregateQuery(tableName string, options *FindAllOptions, agg Aggregate) (string, []interface{}, error) {
+	expr, err := agg.expr()
+	if err != nil {
+		return "", nil, err
+	}
+	if agg.Column != "" && agg.Column != "*" && !options.filterConfig().allowed(agg.Column) {
+		return "", nil, fmt.Errorf("%w: %q", ErrInvalidColumn, agg.Column)
+	}
+	aggOptions := options.unordered()
+	aggOptions.Fields = []string{expr}
+	aggOptions.SelectPrefix = nil
+	aggOptions.Limit = 0
+	aggOptions.Offset = 0
+	sb, err := findAllBuilder(tableName, &aggOptions)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlQuery, args := sb.Build()
+	return sqlQuery, args, nil
+}
+
+// DeepPageQuery returns compiled SELECT string and args of the page selected by Limit and Offset ordered by keyColumn,
+// like SELECT * FROM players WHERE key >= (SELECT key FROM players ORDER BY key LIMIT 1 OFFSET $1) ORDER BY key LIMIT 10.
+// The subquery skips the Offset rows with an index only scan of keyColumn, that must be unique and indexed, and the
+// filters are applied to both queries. OrderBy, Orders and TieBreaker are replaced by keyColumn.
+func DeepPageQuery(tableName, keyColumn string, options *FindAllOptions) (string, []interface{}, error) {
+	if !isColumnName(keyColumn) {
+		return "", nil, fmt.Errorf("%w: %q", ErrInvalidColumn, keyColumn)
+	}
+	key := qualifyColumn(options.TableAlias, keyColumn)
+	innerOptions := options.unordered()
+	innerOptions.Fields = []string{keyColumn}
+	innerOptions.SelectPrefix = nil
+	innerOptions.Limit = 0
+	innerOptions.Offset = 0
+	innerOptions.Fetch = false
+	inner, err := findAllBuilder(tableName, &innerOptions)
+	if err != nil {
+		return "", nil, err
+	}
+	_, offset, err := parseLimitOffset(options.Limit, options.Offset)
+	if err != nil {
+		return "", nil, err
+	}
+	inner.OrderBy(key)
+	inner.SQL("LIMIT 1 OFFSET " + inner.Var(max(offset, 0)))
+	outerOptions := options.unordered()
+	outerOptions.Offset = 0
+	outerOptions.OrderBy = key
+	sb, err := findAllBuilder(tableName, &outerOptions)
+	if err != nil {
+		return "", nil, err
+	}
+	sb.Where(fmt.Sprintf("%s >= (%s)", key, sb.Var(inner)))
+	sqlQuery, args := buildSelect(sb, options.Flavor, options.lockConfig())
+	return sqlQuery, args, nil
+}
+
+// ClaimQuery returns compiled SELECT string and args that claims up to limit rows of a work queue,
+// using FOR UPDATE SKIP LOCKED so the rows locked by other workers are skipped instead of waited.
+// OrderBy, Orders, TieBreaker or the seeded random order is required to claim the rows in a predictable order, ErrMissingOrderBy is returned otherwise.
+// SQLite has no row locking, the lock clause is omitted.
+func ClaimQuery(tableName string, options *FindAllOptions, limit int) (string, []interface{}, error) {
+	if orderBy, _ := options.orderBy(); orderBy == "" && options.RandomSeedColumn == "" {
+		return "", nil, ErrMissingOrderBy
+	}
+	if limit <= 0 {
+		return "", nil, fmt.Errorf("%w: %d", ErrInvalidLimit, limit)
+	}
+	claimOptions := options.WithForUpdate("SKIP LOCKED").WithLimit(limit).WithOffset(0)
+	claimOptions.LockWait = 0
+	return FindAllQueryE(tableName, claimOptions)
+}
+
+// EncodeCursor returns an opaque pagination token with the sort values of the last row, like
+// {"id": 42, "created_at": createdAt}, to be sent to the clients and applied with WithCursorToken.
+// The values are JSON encoded, so a time.Time is decoded as a RFC 3339 string. An empty string is returned
+// when a value can't be encoded.
+func EncodeCursor(values map[string]interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the sort values of a token created by EncodeCursor or an ErrInvalidValue error
+// if the token is malformed. The integer numbers are decoded as int64 and the other numbers as float64.
+func DecodeCursor(token string) (map[string]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor token: %v", ErrInvalidValue, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil || values == nil {
+		return nil, fmt.Errorf("%w: cursor token: malformed values", ErrInvalidValue)
+	}
+	for key, value := range values {
+		number, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		if i, err := number.Int64(); err == nil {
+			values[key] = i
+		} else if f, err := number.Float64(); err == nil {
+			values[key] = f
+		}
+	}
+	return values, nil
+}
+
+func findAllCursorBuilder(tableName string, options *FindAllOptions) (*sqlbuilder.SelectBuilder, error) {
+	cursorOptions := options.unordered()
+	cursorOptions.Fetch = false
+	sb, err := findAllBuilder(tableName, &cursorOptions)
+	sb.Offset(-1)
+	cursorColumn := qualifyColumn(options.TableAlias, options.CursorColumn)
+	switch strings.ToLower(options.CursorDirection) {
+	case "", "asc":
+		if options.CursorValue != nil {
+			sb.Where(sb.GreaterThan(cursorColumn, options.CursorValue))
+		}
+		sb.OrderBy(cursorColumn + " ASC")
+	case "desc":
+		if options.CursorValue != nil {
+			sb.Where(sb.LessThan(cursorColumn, options.CursorValue))
+		}
+		sb.OrderBy(cursorColumn + " DESC")
+	default:
+		if err == nil {
+			err = fmt.Errorf("%w: %q", ErrInvalidDirection, options.CursorDirection)
+		}
+	}
+	return sb, err
+}
+
+// FindAllCursorQuery returns compiled SELECT string and args using keyset pagination.
+// The rows after CursorValue are selected ordered by CursorColumn, Offset and OrderBy are ignored.
+// A nil CursorValue returns the first page.
+func FindAllCursorQuery(tableName string, options *FindAllOptions) (string, []interface{}) {
+	sb, _ := findAllCursorBuilder(tableName, options)
+	return buildSelect(sb, options.Flavor, options.lockConfig())
+}
+
+// FindAllCursorQueryE returns compiled SELECT string and args using keyset pagination
+// or an error if any filter or the cursor direction is invalid.
+func FindAllCursorQueryE(tableName string, options *FindAllOptions) (string, []interface{}, error) {
+	sb, err := findAllCursorBuilder(tableName, options)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlQuery, args := buildSelect(sb, options.Flavor, options.lockConfig())
+	return sqlQuery, args, nil
+}
+
+// filterArgNames returns a unique name for each arg added by the filters, in key order.
+// The name is the filter key with the dots replaced by "_", like "age_gte" or "teams_id_in", and a 1-based
+// index is appended when the filter has more than one value, like "id_in_1".
+func filterArgNames(config filterConfig, filters map[string]interface{}) []string {
+	var names []string
+	for _, key := range sortedKeys(filters) {
+		cond := &sqlbuilder.Cond{Args: &sqlbuilder.Args{}}
+		expr, err := parseFilter(cond, config, key, filters[key])
+		if err != nil || expr == "" {
+			continue
+		}
+		_, values := cond.Args.CompileWithFlavor(expr, config.flavor.builderFlavor())
+		name := argName(key)
+		for i := range values {
+			if len(values) > 1 {
+				names = append(names, fmt.Sprintf("%s_%d", name, i+1))
+			} else {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// valuesJoinArgNames returns a name for each arg added by the values joins, the alias with a 1-based index
+// like "ids_1", the invalid joins add no args.
+func valuesJoinArgNames(flavor Flavor, joins []ValuesJoin) []string {
+	var names []string
+	for _, join := range joins {
+		sb := sqlbuilder.NewSelectBuilder()
+		if _, err := valuesJoinExpr(sb, flavor, join); err != nil {
+			continue
+		}
+		for i := 0; i < len(join.Rows)*len(join.Columns); i++ {
+			names = append(names, fmt.Sprintf("%s_%d", join.Alias, i+1))
+		}
+	}
+	return names
+}
+
+// argName returns key with every character that is not a letter, a digit or "_" replaced by "_",
+// so it can be used as a named placeholder.
+func argName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// uniqueArgNames renames the names already used by a previous arg with a 1-based suffix, like "id_gt_2",
+// so the sanitized filter keys "id.gt" and "id_gt" don't bind the same arg.
+func uniqueArgNames(names []string) []string {
+	used := make(map[string]bool, len(names))
+	for i, name := range names {
+		for n := 2; used[names[i]]; n++ {
+			names[i] = fmt.Sprintf("%s_%d", name, n)
+		}
+		used[names[i]] = true
+	}
+	return names
+}
+
+// namePlaceholders replaces the positional placeholders of sqlQuery with ":name", the quoted literals are kept as they are.
+// Every other ":", like the one of the PostgreSQL "::" casts, is escaped as "::" since sqlx reads it as an escaped colon.
+func namePlaceholders(flavor Flavor, sqlQuery string, names []string) string {
+	var buf strings.Builder
+	quoted := false
+	next := 0
+	for i := 0; i < len(sqlQuery); i++ {
+		c := sqlQuery[i]
+		switch {
+		case c == ':':
+			buf.WriteString("::")
+			continue
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case flavor == PostgreSQLFlavor && c == '$':
+			j := i + 1
+			for j < len(sqlQuery) && sqlQuery[j] >= '0' && sqlQuery[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				n, _ := strconv.Atoi(sqlQuery[i+1 : j])
+				buf.WriteString(":" + names[n-1])
+				i = j - 1
+				continue
+			}
+		case flavor != PostgreSQLFlavor && c == '?':
+			buf.WriteString(":" + names[next])
+			next++
+			continue
+		}
+		buf.WriteByte(c)
+	}
+	return buf.String()
+}
+
+// FindAllQueryNamed returns compiled SELECT string with ":name" placeholders and the named args,
+// like the sqlx named queries expect. The args are named after the filter keys, like "id" for the "id"
+// filter and "age_gte" for the "age.gte" filter, multiple values get a 1-based index like "id_in_1".
+// The args of RecursiveCTE are named "cte_1", "cte_2" and so on, the ones of ValuesJoins after the join alias,
+// like "ids_1", and the args of the other clauses, like the predicates of the With* filter helpers, are named
+// after their position, like "arg_4". A name already used by a previous arg gets a suffix, like "id_gt_2".
+// The other colons of the query, like the ones of the PostgreSQL casts, are escaped as "::" for sqlx.
+func FindAllQueryNamed(tableName string, options *FindAllOptions) (string, map[string]interface{}) {
+	sqlQuery, args := FindAllQuery(tableName, options)
+	var names []string
+	if cte := options.RecursiveCTE; cte != nil {
+		_, cteArgs := sqlbuilder.Build(cte.BaseQuery+" UNION ALL "+cte.RecursiveQuery, cte.Args...).Build()
+		for i := range cteArgs {
+			names = append(names, fmt.Sprintf("cte_%d", i+1))
 		}
 	}
+	names = append(names, valuesJoinArgNames(options.Flavor, options.ValuesJoins)...)
+	names = append(names, filterArgNames(options.filterConfig(), options.Filters)...)
+	for i := len(names); i < len(args); i++ {
+		names = append(names, fmt.Sprintf("arg_%d", i+1))
+	}
+	names = uniqueArgNames(names)
+	namedArgs := make(map[string]interface{}, len(args))
+	for i := range args {
+		namedArgs[names[i]] = args[i]
+	}
+	return namePlaceholders(options.Flavor, sqlQuery, names), namedArgs
+}
+
+func topNPerGroupBuilder(tableName, partitionColumn, orderColumn string, n int, options *FindAllOptions) (*sqlbuilder.SelectBuilder, error) {
+	innerOptions := options.unordered()
+	innerOptions.Limit = 0
+	innerOptions.Offset = 0
+	innerOptions.Fields = append(
+		append(make([]string, 0, len(options.Fields)+1), options.Fields...),
+		fmt.Sprintf("ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS rn", partitionColumn, orderColumn),
+	)
+	inner, innerErr := findAllBuilder(tableName, &innerOptions)
+	limit, offset, limitErr := parseLimitOffset(options.Limit, options.Offset)
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.SetFlavor(options.Flavor.builderFlavor())
+	sb.Select("*").From(sb.BuilderAs(inner, "ranked")).Limit(limit).Offset(offset)
+	sb.Where(sb.LessEqualThan("rn", n))
+	orderBy, orderErr := options.orderBy()
+	if orderBy != "" {
+		sb.OrderBy(orderBy)
+	}
+	return sb, firstError(innerErr, limitErr, orderErr)
+}
+
+// TopNPerGroupQuery returns compiled SELECT string and args of the first n rows of each partitionColumn group
+// ordered by orderColumn. The filters are applied to the inner query, OrderBy, Limit and Offset to the outer query
+// and the row number is returned as the "rn" column.
+func TopNPerGroupQuery(tableName, partitionColumn, orderColumn string, n int, options *FindAllOptions) (string, []interface{}) {
+	sb, _ := topNPerGroupBuilder(tableName, partitionColumn, orderColumn, n, options)
 	return sb.Build()
 }
 
+// TopNPerGroupQueryE returns compiled SELECT string and args like TopNPerGroupQuery or an error if any option is invalid.
+func TopNPerGroupQueryE(tableName, partitionColumn, orderColumn string, n int, options *FindAllOptions) (string, []interface{}, error) {
+	sb, err := topNPerGroupBuilder(tableName, partitionColumn, orderColumn, n, options)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlQuery, args := sb.Build()
+	return sqlQuery, args, nil
+}
+
+func antiJoinBuilder(leftTable, rightTable, onExpr, rightKey string, options *FindAllOptions) (*sqlbuilder.SelectBuilder, error) {
+	// The filters and the soft delete column are qualified with leftTable or TableAlias, the columns may exist in both tables.
+	joinOptions := *options
+	joinOptions.Filters = nil
+	joinOptions.predicates = nil
+	joinOptions.SoftDeleteColumn = ""
+	sb, limitErr := findAllBuilder(leftTable, &joinOptions)
+	sb.JoinWithOption(sqlbuilder.LeftJoin, quoteTableName(options.Flavor, options.Schema, rightTable), onExpr)
+	config := options.filterConfig()
+	if config.qualifier == "" {
+		config.qualifier = leftTable
+	}
+	filterErr := parseSelectFilters(sb, config, options.Filters, options.predicates)
+	if options.SoftDeleteColumn != "" && !options.IncludeDeleted {
+		sb.Where(sb.IsNull(config.column(options.SoftDeleteColumn)))
+	}
+	sb.Where(sb.IsNull(rightKey))
+	return sb, firstError(limitErr, filterErr)
+}
+
+// AntiJoinQuery returns compiled SELECT string and args of the leftTable rows without a matching rightTable row,
+// using LEFT JOIN rightTable ON onExpr WHERE rightKey IS NULL. The filter fields are qualified with leftTable
+// and rightKey should be qualified with rightTable, like "contracts.id".
+func AntiJoinQuery(leftTable, rightTable, onExpr, rightKey string, options *FindAllOptions) (string, []interface{}) {
+	sb, _ := antiJoinBuilder(leftTable, rightTable, onExpr, rightKey, options)
+	return buildSelect(sb, options.Flavor, options.lockConfig())
+}
+
+// AntiJoinQueryE returns compiled SELECT string and args like AntiJoinQuery or an error if any option is invalid.
+func AntiJoinQueryE(leftTable, rightTable, onExpr, rightKey string, options *FindAllOptions) (string, []interface{}, error) {
+	sb, err := antiJoinBuilder(leftTable, rightTable, onExpr, rightKey, options)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlQuery, args := buildSelect(sb, options.Flavor, options.lockConfig())
+	return sqlQuery, args, nil
+}
+
 // InsertQuery returns compiled INSERT string and args.
 func InsertQuery(flavor Flavor, tag, tableName string, structValue interface{}) (string, []interface{}) {
-	theStruct := sqlbuilder.NewStruct(structValue).For(sqlbuilder.Flavor(flavor))
-	ib := theStruct.WithTag(tag).InsertInto(tableName, structValue)
+	theStruct := sqlbuilder.NewStruct(structValue).For(flavor.builderFlavor())
+	ib := theStruct.WithTag(tag).InsertInto(quoteTableName(flavor, "", tableName), structValue)
+	return ib.Build()
+}
+
+// InsertMapQuery returns compiled INSERT string and args from values, a row built dynamically without a tagged struct.
+// The columns are sorted to keep the query deterministic and the args follow the same order.
+func InsertMapQuery(flavor Flavor, tableName string, values map[string]interface{}) (string, []interface{}) {
+	ib := sqlbuilder.NewInsertBuilder()
+	ib.SetFlavor(flavor.builderFlavor())
+	ib.InsertInto(quoteTableName(flavor, "", tableName))
+	columns := sortedKeys(values)
+	row := make([]interface{}, len(columns))
+	for i, column := range columns {
+		row[i] = values[column]
+	}
+	ib.Cols(columns...).Values(row...)
 	return ib.Build()
 }
 
+// InsertIgnoreQuery returns compiled INSERT string and args that skips the row when it conflicts with an existing one.
+// PostgreSQLFlavor and SQLiteFlavor render "ON CONFLICT DO NOTHING", MySQLFlavor and MariaDBFlavor render "INSERT IGNORE".
+func InsertIgnoreQuery(flavor Flavor, tag, tableName string, structValue interface{}) (string, []interface{}) {
+	theStruct := sqlbuilder.NewStruct(structValue).For(flavor.builderFlavor()).WithTag(tag)
+	switch flavor {
+	case MySQLFlavor, MariaDBFlavor:
+		return theStruct.InsertIgnoreInto(quoteTableName(flavor, "", tableName), structValue).Build()
+	default:
+		ib := theStruct.InsertInto(quoteTableName(flavor, "", tableName), structValue)
+		ib.SQL("ON CONFLICT DO NOTHING")
+		return ib.Build()
+	}
+}
+
+// SnapshotStatement returns the PostgreSQL SET TRANSACTION SNAPSHOT statement that imports the snapshot exported
+// by pg_export_snapshot() in another transaction, so both transactions see the same data. It must be the first
+// statement of a REPEATABLE READ or SERIALIZABLE transaction. An empty string is returned when id is malformed.
+func SnapshotStatement(id string) string {
+	if !isSnapshotID(id) {
+		return ""
+	}
+	return "SET TRANSACTION SNAPSHOT '" + id + "'"
+}
+
+// UnnestWithOrdinalityQuery returns the PostgreSQL query that expands values into rows with their 1-based position,
+// like SELECT * FROM unnest($1::text[]) WITH ORDINALITY AS t(val, pos). The values are bound as a single text
+// array literal and a nil value is a NULL element. An empty string is returned for the other flavors or when
+// a column name is invalid.
+func UnnestWithOrdinalityQuery(flavor Flavor, values []interface{}, valColumn, posColumn string) (string, []interface{}) {
+	if flavor != PostgreSQLFlavor || !isColumnName(valColumn) || !isColumnName(posColumn) {
+		return "", nil
+	}
+	elements := make([]string, len(values))
+	for i, value := range values {
+		if isNil(value) {
+			elements[i] = "NULL"
+		} else {
+			elements[i] = arrayElement(fmt.Sprint(value))
+		}
+	}
+	literal := "{" + strings.Join(elements, ",") + "}"
+	sb := sqlbuilder.NewSelectBuilder()
+	sb.SetFlavor(flavor.builderFlavor())
+	sb.Select("*").From(fmt.Sprintf("unnest(%s::text[]) WITH ORDINALITY AS t(%s, %s)", sb.Var(literal), valColumn, posColumn))
+	return sb.Build()
+}
+
 // UpdateQuery returns compiled UPDATE string and args.
 func UpdateQuery(flavor Flavor, tag, tableName string, id interface{}, structValue interface{}) (string, []interface{}) {
-	theStruct := sqlbuilder.NewStruct(structValue).For(sqlbuilder.Flavor(flavor))
-	ub := theStruct.WithTag(tag).Update(tableName, structValue)
+	theStruct := sqlbuilder.NewStruct(structValue).For(flavor.builderFlavor())
+	ub := theStruct.WithTag(tag).Update(quoteTableName(flavor, "", tableName), structValue)
+	ub.Where(ub.Equal("id", id))
+	return ub.Build()
+}
+
+// UpdateMapQuery returns compiled UPDATE string and args from values, a partial update built without a tagged struct.
+// The assignments are sorted by column to keep the query deterministic and the id is the last arg.
+func UpdateMapQuery(flavor Flavor, tableName string, values map[string]interface{}, id interface{}) (string, []interface{}) {
+	ub := sqlbuilder.NewUpdateBuilder()
+	ub.SetFlavor(flavor.builderFlavor())
+	ub.Update(quoteTableName(flavor, "", tableName))
+	columns := sortedKeys(values)
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		assignments[i] = ub.Assign(column, values[column])
+	}
+	ub.Set(assignments...)
 	ub.Where(ub.Equal("id", id))
 	return ub.Build()
 }
@@ -221,36 +2049,391 @@ func UpdateQuery(flavor Flavor, tag, tableName string, id interface{}, structVal
 // DeleteQuery returns compiled DELETE string and args.
 func DeleteQuery(flavor Flavor, tableName string, id interface{}) (string, []interface{}) {
 	db := sqlbuilder.NewDeleteBuilder()
-	db.SetFlavor(sqlbuilder.Flavor(flavor))
-	db.DeleteFrom(tableName)
+	db.SetFlavor(flavor.builderFlavor())
+	db.DeleteFrom(quoteTableName(flavor, "", tableName))
 	db.Where(db.Equal("id", id))
 	return db.Build()
 }
 
-// UpdateWithOptionsQuery returns compiled UPDATE string and args from UpdateOptions.
-func UpdateWithOptionsQuery(tableName string, options *UpdateOptions) (string, []interface{}) {
+// UpsertQuery returns compiled INSERT string and args that updates the row when it already exists.
+// PostgreSQLFlavor and SQLiteFlavor render "ON CONFLICT (...) DO UPDATE" and require ConflictColumns,
+// PostgreSQLFlavor may use ConflictConstraint instead and may add the ConflictWhere predicate of a partial index.
+// MySQLFlavor and MariaDBFlavor render "ON DUPLICATE KEY UPDATE".
+// RETURNING is supported on PostgreSQL, SQLite 3.35+ and MariaDB 10.5+, MySQL returns ErrReturningNotSupported.
+func UpsertQuery(tag, tableName string, structValue interface{}, options *UpsertOptions) (string, []interface{}, error) {
+	flavor := options.Flavor
+	if len(options.Returning) > 0 && flavor == MySQLFlavor {
+		return "", nil, ErrReturningNotSupported
+	}
+	theStruct := sqlbuilder.NewStruct(structValue).For(flavor.builderFlavor())
+	ib := theStruct.WithTag(tag).InsertInto(quoteTableName(flavor, "", tableName), structValue)
+	updateColumns := options.UpdateColumns
+	if len(updateColumns) == 0 {
+		for _, column := range theStruct.ColumnsForTag(tag) {
+			if !containsString(options.ConflictColumns, column) {
+				updateColumns = append(updateColumns, column)
+			}
+		}
+	}
+	assignments := make([]string, len(updateColumns))
+	switch flavor {
+	case MySQLFlavor, MariaDBFlavor:
+		for i, column := range updateColumns {
+			assignments[i] = fmt.Sprintf("%s = VALUES(%s)", column, column)
+		}
+		ib.SQL("ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", "))
+	default:
+		useConstraint := flavor == PostgreSQLFlavor && options.ConflictConstraint != ""
+		if len(options.ConflictColumns) == 0 && !useConstraint {
+			return "", nil, ErrMissingConflictColumns
+		}
+		for i, column := range updateColumns {
+			assignments[i] = fmt.Sprintf("%s = EXCLUDED.%s", column, column)
+		}
+		conflict := fmt.Sprintf("ON CONFLICT (%s)", strings.Join(options.ConflictColumns, ", "))
+		if flavor == PostgreSQLFlavor && options.ConflictWhere != "" {
+			conflict += " WHERE " + sqlbuilder.Escape(options.ConflictWhere)
+		}
+		if useConstraint {
+			conflict = "ON CONFLICT ON CONSTRAINT " + options.ConflictConstraint
+		}
+		if len(assignments) == 0 {
+			ib.SQL(conflict + " DO NOTHING")
+		} else {
+			ib.SQL(conflict + " DO UPDATE SET " + strings.Join(assignments, ", "))
+		}
+	}
+	if len(options.Returning) > 0 {
+		ib.SQL("RETURNING " + strings.Join(options.Returning, ", "))
+	}
+	sqlQuery, args := ib.Build()
+	return sqlQuery, args, nil
+}
+
+func containsString(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SoftDeleteOption is a functional option for SoftDeleteQuery function.
+type SoftDeleteOption func(*softDeleteConfig)
+
+type softDeleteConfig struct {
+	deletedAt time.Time
+}
+
+// WithDeletedAt sets the timestamp used by SoftDeleteQuery instead of the current time.
+func WithDeletedAt(t time.Time) SoftDeleteOption {
+	return func(c *softDeleteConfig) {
+		c.deletedAt = t
+	}
+}
+
+// SoftDeleteQuery returns compiled UPDATE string and args that sets column to the current time instead of deleting the row.
+func SoftDeleteQuery(flavor Flavor, tableName, column string, id interface{}, options ...SoftDeleteOption) (string, []interface{}) {
+	config := softDeleteConfig{deletedAt: time.Now().UTC()}
+	for _, option := range options {
+		option(&config)
+	}
+	ub := sqlbuilder.NewUpdateBuilder()
+	ub.SetFlavor(flavor.builderFlavor())
+	ub.Update(quoteTableName(flavor, "", tableName))
+	ub.Set(ub.Assign(column, config.deletedAt))
+	ub.Where(ub.Equal("id", id))
+	return ub.Build()
+}
+
+// InsertDefaultValuesQuery returns compiled INSERT string that inserts a row with the default value of every column.
+func InsertDefaultValuesQuery(flavor Flavor, tableName string) string {
+	tableName = quoteTableName(flavor, "", tableName)
+	if flavor == MySQLFlavor || flavor == MariaDBFlavor {
+		return "INSERT INTO " + tableName + " () VALUES ()"
+	}
+	return "INSERT INTO " + tableName + " DEFAULT VALUES"
+}
+
+// TruncateOption is a functional option for TruncateQuery function.
+type TruncateOption func(*truncateConfig)
+
+type truncateConfig struct {
+	restartIdentity bool
+}
+
+// WithRestartIdentity adds RESTART IDENTITY to the TRUNCATE statement, it is only used by PostgreSQL.
+func WithRestartIdentity() TruncateOption {
+	return func(c *truncateConfig) {
+		c.restartIdentity = true
+	}
+}
+
+// TruncateQuery returns compiled TRUNCATE string that removes all rows from tableName.
+// SQLite has no TRUNCATE statement, a DELETE FROM is used instead.
+func TruncateQuery(flavor Flavor, tableName string, options ...TruncateOption) string {
+	config := truncateConfig{}
+	for _, option := range options {
+		option(&config)
+	}
+	tableName = quoteTableName(flavor, "", tableName)
+	switch flavor {
+	case SQLiteFlavor:
+		return "DELETE FROM " + tableName
+	case PostgreSQLFlavor:
+		if config.restartIdentity {
+			return "TRUNCATE TABLE " + tableName + " RESTART IDENTITY"
+		}
+	}
+	return "TRUNCATE TABLE " + tableName
+}
+
+// dmlLimitError returns the error of the LIMIT of an UPDATE or DELETE, which is only supported on MySQL and MariaDB
+// and not allowed on the multi-table form rendered with joins.
+func dmlLimitError(flavor Flavor, limit int, joins []Join) error {
+	if limit < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidLimit, limit)
+	}
+	if flavor != MySQLFlavor && flavor != MariaDBFlavor {
+		return fmt.Errorf("%w: LIMIT %d", ErrUnsupportedFlavor, limit)
+	}
+	if len(joins) > 0 {
+		return fmt.Errorf("%w: LIMIT %d with joins", ErrInvalidLimit, limit)
+	}
+	return nil
+}
+
+func updateBuilder(tableName string, options *UpdateOptions) (*sqlbuilder.UpdateBuilder, error) {
 	ub := sqlbuilder.NewUpdateBuilder()
-	ub.SetFlavor(sqlbuilder.Flavor(options.Flavor))
-	ub.Update(tableName)
+	ub.SetFlavor(options.Flavor.builderFlavor())
+	table := quoteTableName(options.Flavor, options.Schema, tableName)
+	config := options.filterConfig()
+	joinFrom := len(options.Joins) > 0 && options.Flavor != MySQLFlavor && options.Flavor != MariaDBFlavor
+	if len(options.Joins) > 0 {
+		config.qualifier = tableName
+		if !joinFrom {
+			joins := make([]string, len(options.Joins))
+			for i, join := range options.Joins {
+				joins[i] = fmt.Sprintf("JOIN %s ON %s", quoteTableName(options.Flavor, "", join.Table), join.On)
+			}
+			table += " " + strings.Join(joins, " ")
+		}
+	}
+	ub.Update(table)
+	// The JOIN form of MySQL and MariaDB accepts the columns of every table in SET, so they are qualified
+	// with the target table, PostgreSQL and SQLite only accept the target table columns unqualified.
 	var assignments []string
 	for key, value := range options.Assignments {
+		if len(options.Joins) > 0 && !joinFrom {
+			key = config.column(key)
+		}
 		assignments = append(assignments, ub.Assign(key, value))
 	}
 	sort.Strings(assignments)
 	ub = ub.Set(assignments...)
-	for key, value := range options.Filters {
-		parseUpdateFilter(ub, key, value)
+	if joinFrom {
+		tables := make([]string, len(options.Joins))
+		for i, join := range options.Joins {
+			tables[i] = quoteTableName(options.Flavor, "", join.Table)
+		}
+		ub.SQL("FROM " + strings.Join(tables, ", "))
+		for _, join := range options.Joins {
+			ub.Where(join.On)
+		}
+	}
+	err := parseUpdateFilters(ub, config, options.Filters, options.predicates)
+	if options.ChangedOnly && len(options.Assignments) > 0 {
+		changed := make([]string, 0, len(options.Assignments))
+		for _, key := range sortedKeys(options.Assignments) {
+			changed = append(changed, distinctFromExpr(&ub.Cond, options.Flavor, config.column(key), options.Assignments[key]))
+		}
+		ub.Where(ub.Or(changed...))
+	}
+	if options.Limit != 0 {
+		if limitErr := dmlLimitError(options.Flavor, options.Limit, options.Joins); limitErr != nil {
+			err = firstError(err, limitErr)
+		} else {
+			ub.Limit(options.Limit)
+		}
+	}
+	if len(options.Returning) > 0 {
+		if options.Flavor == MySQLFlavor || options.Flavor == MariaDBFlavor {
+			return ub, firstError(err, ErrReturningNotSupported)
+		}
+		ub.SQL("RETURNING " + strings.Join(options.Returning, ", "))
 	}
+	return ub, err
+}
+
+// UpdateWithOptionsQuery returns compiled UPDATE string and args from UpdateOptions.
+// Filters with unknown operators and unsupported RETURNING clauses are ignored, use UpdateWithOptionsQueryE to get an error instead.
+func UpdateWithOptionsQuery(tableName string, options *UpdateOptions) (string, []interface{}) {
+	ub, _ := updateBuilder(tableName, options)
 	return ub.Build()
 }
 
-// DeleteWithOptionsQuery returns compiled DELETE string and args from DeleteOptions.
-func DeleteWithOptionsQuery(tableName string, options *DeleteOptions) (string, []interface{}) {
+// UpdateWithOptionsQueryE returns compiled UPDATE string and args from UpdateOptions or an error if any filter is invalid.
+func UpdateWithOptionsQueryE(tableName string, options *UpdateOptions) (string, []interface{}, error) {
+	ub, err := updateBuilder(tableName, options)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlQuery, args := ub.Build()
+	return sqlQuery, args, nil
+}
+
+// deleteJoin adds the Joins of options to db with the flavor syntax, DELETE FROM table USING on PostgreSQL,
+// DELETE table FROM table JOIN on MySQL and MariaDB and a rowid subquery on SQLite, and applies the filters.
+func deleteJoin(db *sqlbuilder.DeleteBuilder, tableName string, config filterConfig, options *DeleteOptions) error {
+	table := quoteTableName(options.Flavor, options.Schema, tableName)
+	switch options.Flavor {
+	case PostgreSQLFlavor:
+		tables := make([]string, len(options.Joins))
+		for i, join := range options.Joins {
+			tables[i] = quoteTableName(options.Flavor, "", join.Table)
+		}
+		db.DeleteFrom(table + " USING " + strings.Join(tables, ", "))
+		for _, join := range options.Joins {
+			db.Where(join.On)
+		}
+		return parseDeleteFilters(db, config, options.Filters, options.predicates)
+	case SQLiteFlavor:
+		sb := sqlbuilder.NewSelectBuilder()
+		sb.SetFlavor(options.Flavor.builderFlavor())
+		selectFrom(sb, options.Flavor, options.Schema, tableName, "", []string{tableName + ".rowid"}, options.Joins)
+		err := parseSelectFilters(sb, config, options.Filters, options.predicates)
+		db.DeleteFrom(table)
+		db.Where(fmt.Sprintf("rowid IN (%s)", db.Var(sb)))
+		return err
+	default:
+		// go-sqlbuilder only renders DELETE FROM table, the multi-table DELETE is written before the WHERE clause instead.
+		joins := make([]string, len(options.Joins))
+		for i, join := range options.Joins {
+			joins[i] = fmt.Sprintf("JOIN %s ON %s", quoteTableName(options.Flavor, "", join.Table), join.On)
+		}
+		db.SQL(fmt.Sprintf("DELETE %s FROM %s %s", table, table, strings.Join(joins, " ")))
+		return parseDeleteFilters(db, config, options.Filters, options.predicates)
+	}
+}
+
+func deleteBuilder(tableName string, options *DeleteOptions) (*sqlbuilder.DeleteBuilder, error) {
 	db := sqlbuilder.NewDeleteBuilder()
-	db.SetFlavor(sqlbuilder.Flavor(options.Flavor))
-	db.DeleteFrom(tableName)
-	for key, value := range options.Filters {
-		parseDeleteFilter(db, key, value)
+	db.SetFlavor(options.Flavor.builderFlavor())
+	var err error
+	if len(options.Joins) == 0 {
+		db.DeleteFrom(quoteTableName(options.Flavor, options.Schema, tableName))
+		err = parseDeleteFilters(db, options.filterConfig(), options.Filters, options.predicates)
+	} else {
+		config := options.filterConfig()
+		config.qualifier = tableName
+		err = deleteJoin(db, tableName, config, options)
+	}
+	if options.Limit != 0 {
+		if limitErr := dmlLimitError(options.Flavor, options.Limit, options.Joins); limitErr != nil {
+			err = firstError(err, limitErr)
+		} else {
+			db.Limit(options.Limit)
+		}
+	}
+	if len(options.Returning) > 0 {
+		// MariaDB only supports RETURNING on the single table DELETE, not on the multiple table one of the joins.
+		if options.Flavor == MySQLFlavor || (options.Flavor == MariaDBFlavor && len(options.Joins) > 0) {
+			return db, firstError(err, ErrReturningNotSupported)
+		}
+		db.SQL("RETURNING " + strings.Join(options.Returning, ", "))
 	}
+	return db, err
+}
+
+// DeleteWithOptionsQuery returns compiled DELETE string and args from DeleteOptions.
+// Filters with unknown operators and unsupported RETURNING clauses are ignored, use DeleteWithOptionsQueryE to get an error instead.
+func DeleteWithOptionsQuery(tableName string, options *DeleteOptions) (string, []interface{}) {
+	db, _ := deleteBuilder(tableName, options)
 	return db.Build()
 }
+
+// DeleteWithOptionsQueryE returns compiled DELETE string and args from DeleteOptions or an error if any filter is invalid.
+func DeleteWithOptionsQueryE(tableName string, options *DeleteOptions) (string, []interface{}, error) {
+	db, err := deleteBuilder(tableName, options)
+	if err != nil {
+		return "", nil, err
+	}
+	sqlQuery, args := db.Build()
+	return sqlQuery, args, nil
+}
+
+// Statement holds a compiled sql string and its args.
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// CascadeChild describes a child table whose rows reference the parent table.
+type CascadeChild struct {
+	TableName  string
+	ForeignKey string
+	ParentKey  string
+}
+
+func cascadeDeleteStatements(tableName string, options *DeleteOptions, children []CascadeChild) ([]Statement, error) {
+	var firstErr error
+	statements := make([]Statement, 0, len(children)+1)
+	for _, child := range children {
+		parentKey := child.ParentKey
+		if parentKey == "" {
+			parentKey = "id"
+		}
+		// The subquery selects the parent rows the same way deleteJoin does, with the joins and the qualified filters.
+		config := options.filterConfig()
+		if len(options.Joins) > 0 {
+			config.qualifier = tableName
+			parentKey = qualifyColumn(tableName, parentKey)
+		}
+		sb := sqlbuilder.NewSelectBuilder()
+		sb.SetFlavor(options.Flavor.builderFlavor())
+		selectFrom(sb, options.Flavor, options.Schema, tableName, "", []string{parentKey}, options.Joins)
+		if err := parseSelectFilters(sb, config, options.Filters, options.predicates); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		db := sqlbuilder.NewDeleteBuilder()
+		db.SetFlavor(options.Flavor.builderFlavor())
+		db.DeleteFrom(quoteTableName(options.Flavor, "", child.TableName))
+		db.Where(db.In(child.ForeignKey, sb))
+		sqlQuery, args := db.Build()
+		statements = append(statements, Statement{SQL: sqlQuery, Args: args})
+	}
+	// The children subqueries can't be limited to the parent rows deleted by a LIMIT, so it's not rendered.
+	if options.Limit != 0 {
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%w: LIMIT %d with cascade", ErrInvalidLimit, options.Limit)
+		}
+		unlimited := *options
+		unlimited.Limit = 0
+		options = &unlimited
+	}
+	db, err := deleteBuilder(tableName, options)
+	if err != nil && firstErr == nil {
+		firstErr = err
+	}
+	sqlQuery, args := db.Build()
+	statements = append(statements, Statement{SQL: sqlQuery, Args: args})
+	return statements, firstErr
+}
+
+// CascadeDeleteQuery returns compiled DELETE statements for the children tables followed by the parent table.
+// The children rows are matched with a subquery using the parent filters, so the statements must be executed in order.
+// ParentKey defaults to "id" when empty.
+func CascadeDeleteQuery(tableName string, options *DeleteOptions, children ...CascadeChild) []Statement {
+	statements, _ := cascadeDeleteStatements(tableName, options, children)
+	return statements
+}
+
+// CascadeDeleteQueryE returns compiled DELETE statements like CascadeDeleteQuery or an error if any filter is invalid.
+func CascadeDeleteQueryE(tableName string, options *DeleteOptions, children ...CascadeChild) ([]Statement, error) {
+	statements, err := cascadeDeleteStatements(tableName, options, children)
+	if err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
@@ -1,7 +1,9 @@
 package sqlquery
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/huandu/go-sqlbuilder"
 	"github.com/stretchr/testify/assert"
@@ -27,13 +29,18 @@ func TestParseSelectFilter(t *testing.T) {
 		{"like", "id.like", 1, `SELECT * FROM test_table WHERE id LIKE $1`, []interface{}{1}},
 		{"null true", "id.null", true, `SELECT * FROM test_table WHERE id.null IS NULL`, []interface{}(nil)},
 		{"null false", "id.null", false, `SELECT * FROM test_table WHERE id.null IS NOT NULL`, []interface{}(nil)},
+		{"startswith", "name.startswith", "50%", `SELECT * FROM test_table WHERE name LIKE $1`, []interface{}{`50\%%`}},
+		{"endswith", "name.endswith", "r_10", `SELECT * FROM test_table WHERE name LIKE $1`, []interface{}{`%r\_10`}},
+		{"contains", "name.contains", "dinho", `SELECT * FROM test_table WHERE name LIKE $1`, []interface{}{"%dinho%"}},
+		{"blank true", "name.blank", true, `SELECT * FROM test_table WHERE (name IS NULL OR name = '')`, []interface{}(nil)},
+		{"blank false", "name.blank", false, `SELECT * FROM test_table WHERE (name IS NOT NULL AND name <> '')`, []interface{}(nil)},
 	}
 	for _, tt := range tests {
 		t.Run(tt.kind, func(t *testing.T) {
 			sb := sqlbuilder.NewSelectBuilder()
 			sb.SetFlavor(sqlbuilder.Flavor(PostgreSQLFlavor))
 			sb.Select("*").From("test_table")
-			parseSelectFilter(sb, tt.key, tt.value)
+			assert.NoError(t, parseSelectFilter(sb, filterConfig{}, tt.key, tt.value))
 			sqlQuery, args := sb.Build()
 			assert.Equal(t, tt.expectedSQL, sqlQuery)
 			assert.Equal(t, tt.expectedArgs, args)
@@ -61,6 +68,9 @@ func TestParseUpdateFilter(t *testing.T) {
 		{"like", "id.like", 1, `UPDATE test_table SET field = $1 WHERE id LIKE $2`, []interface{}{"field", 1}},
 		{"null true", "id.null", true, `UPDATE test_table SET field = $1 WHERE id.null IS NULL`, []interface{}{"field"}},
 		{"null false", "id.null", false, `UPDATE test_table SET field = $1 WHERE id.null IS NOT NULL`, []interface{}{"field"}},
+		{"startswith", "name.startswith", "50%", `UPDATE test_table SET field = $1 WHERE name LIKE $2`, []interface{}{"field", `50\%%`}},
+		{"endswith", "name.endswith", "r_10", `UPDATE test_table SET field = $1 WHERE name LIKE $2`, []interface{}{"field", `%r\_10`}},
+		{"contains", "name.contains", "dinho", `UPDATE test_table SET field = $1 WHERE name LIKE $2`, []interface{}{"field", "%dinho%"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.kind, func(t *testing.T) {
@@ -68,7 +78,7 @@ func TestParseUpdateFilter(t *testing.T) {
 			ub.SetFlavor(sqlbuilder.Flavor(PostgreSQLFlavor))
 			ub.Update("test_table")
 			ub.Set(ub.Assign("field", "field"))
-			parseUpdateFilter(ub, tt.key, tt.value)
+			assert.NoError(t, parseUpdateFilter(ub, filterConfig{}, tt.key, tt.value))
 			sqlQuery, args := ub.Build()
 			assert.Equal(t, tt.expectedSQL, sqlQuery)
 			assert.Equal(t, tt.expectedArgs, args)
@@ -96,13 +106,16 @@ func TestParseDeleteFilter(t *testing.T) {
 		{"like", "id.like", 1, `DELETE FROM test_table WHERE id LIKE $1`, []interface{}{1}},
 		{"null true", "id.null", true, `DELETE FROM test_table WHERE id.null IS NULL`, []interface{}(nil)},
 		{"null false", "id.null", false, `DELETE FROM test_table WHERE id.null IS NOT NULL`, []interface{}(nil)},
+		{"startswith", "name.startswith", "50%", `DELETE FROM test_table WHERE name LIKE $1`, []interface{}{`50\%%`}},
+		{"endswith", "name.endswith", "r_10", `DELETE FROM test_table WHERE name LIKE $1`, []interface{}{`%r\_10`}},
+		{"contains", "name.contains", "dinho", `DELETE FROM test_table WHERE name LIKE $1`, []interface{}{"%dinho%"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.kind, func(t *testing.T) {
 			db := sqlbuilder.NewDeleteBuilder()
 			db.SetFlavor(sqlbuilder.Flavor(PostgreSQLFlavor))
 			db.DeleteFrom("test_table")
-			parseDeleteFilter(db, tt.key, tt.value)
+			assert.NoError(t, parseDeleteFilter(db, filterConfig{}, tt.key, tt.value))
 			sqlQuery, args := db.Build()
 			assert.Equal(t, tt.expectedSQL, sqlQuery)
 			assert.Equal(t, tt.expectedArgs, args)
@@ -184,3 +197,2152 @@ func TestDeleteWithOptionsQuery(t *testing.T) {
 	assert.Equal(t, expectedSQLQuery, sqlQuery)
 	assert.Equal(t, expectedArgs, args)
 }
+
+func TestCascadeDeleteQuery(t *testing.T) {
+	expectedStatements := []Statement{
+		{SQL: `DELETE FROM players WHERE team_id IN (SELECT id FROM teams WHERE name = $1)`, Args: []interface{}{"Barcelona"}},
+		{SQL: `DELETE FROM staff WHERE team_code IN (SELECT code FROM teams WHERE name = $1)`, Args: []interface{}{"Barcelona"}},
+		{SQL: `DELETE FROM teams WHERE name = $1`, Args: []interface{}{"Barcelona"}},
+	}
+	options := NewDeleteOptions(PostgreSQLFlavor).WithFilter("name", "Barcelona")
+	statements := CascadeDeleteQuery(
+		"teams",
+		options,
+		CascadeChild{TableName: "players", ForeignKey: "team_id"},
+		CascadeChild{TableName: "staff", ForeignKey: "team_code", ParentKey: "code"},
+	)
+	assert.Equal(t, expectedStatements, statements)
+}
+
+func TestValidateFilters(t *testing.T) {
+	t.Run("valid operators", func(t *testing.T) {
+		filters := map[string]interface{}{"id": 1, "id.lte": 10, "name.like": "R%", "team_id.in": "1,2"}
+		assert.NoError(t, ValidateFilters(filters))
+	})
+
+	t.Run("unknown operator", func(t *testing.T) {
+		filters := map[string]interface{}{"id": 1, "id.ltee": 10}
+		err := ValidateFilters(filters)
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+		assert.Contains(t, err.Error(), "id.ltee")
+	})
+
+	t.Run("popcount operator", func(t *testing.T) {
+		assert.NoError(t, ValidateFilters(map[string]interface{}{"flags.popcount": 2, "flags.popcount.gte": 2}))
+		err := ValidateFilters(map[string]interface{}{"flags.popcount.gtee": 2})
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+		assert.Contains(t, err.Error(), "flags.popcount.gtee")
+	})
+
+	t.Run("qualified keys", func(t *testing.T) {
+		assert.NoError(t, ValidateFilters(map[string]interface{}{"teams.id.in": "1,2", "teams.name.like": "B%"}))
+		err := ValidateFilters(map[string]interface{}{"teams.id.inn": "1,2"})
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+		assert.Contains(t, err.Error(), "teams.id.inn")
+	})
+}
+
+func TestQueryE(t *testing.T) {
+	t.Run("FindQueryE", func(t *testing.T) {
+		sqlQuery, args, err := FindQueryE("players", NewFindOptions(PostgreSQLFlavor).WithFilter("id", 1))
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM players WHERE id = $1`, sqlQuery)
+		assert.Equal(t, []interface{}{1}, args)
+
+		_, _, err = FindQueryE("players", NewFindOptions(PostgreSQLFlavor).WithFilter("id.ltee", 1))
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+	})
+
+	t.Run("FindAllQueryE", func(t *testing.T) {
+		_, _, err := FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithFilter("id.ltee", 1))
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+	})
+
+	t.Run("UpdateWithOptionsQueryE", func(t *testing.T) {
+		options := NewUpdateOptions(PostgreSQLFlavor).WithAssignment("name", "R10").WithFilter("id.ltee", 1)
+		_, _, err := UpdateWithOptionsQueryE("players", options)
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+	})
+
+	t.Run("DeleteWithOptionsQueryE", func(t *testing.T) {
+		_, _, err := DeleteWithOptionsQueryE("players", NewDeleteOptions(PostgreSQLFlavor).WithFilter("id.ltee", 1))
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+	})
+
+	t.Run("CascadeDeleteQueryE", func(t *testing.T) {
+		options := NewDeleteOptions(PostgreSQLFlavor).WithFilter("id.ltee", 1)
+		_, err := CascadeDeleteQueryE("teams", options, CascadeChild{TableName: "players", ForeignKey: "team_id"})
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+	})
+
+	t.Run("unknown operator is ignored without E variant", func(t *testing.T) {
+		sqlQuery, args := DeleteWithOptionsQuery("players", NewDeleteOptions(PostgreSQLFlavor).WithFilter("id.ltee", 1))
+		assert.Equal(t, `DELETE FROM players`, sqlQuery)
+		assert.Equal(t, []interface{}(nil), args)
+	})
+}
+
+func TestRangeFilter(t *testing.T) {
+	var tests = []struct {
+		kind          string
+		lowInclusive  bool
+		highInclusive bool
+		expectedSQL   string
+	}{
+		{"inclusive", true, true, `SELECT * FROM players WHERE age >= $1 AND age <= $2`},
+		{"exclusive", false, false, `SELECT * FROM players WHERE age > $1 AND age < $2`},
+		{"low inclusive", true, false, `SELECT * FROM players WHERE age >= $1 AND age < $2`},
+		{"high inclusive", false, true, `SELECT * FROM players WHERE age > $1 AND age <= $2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			options := NewFindOptions(PostgreSQLFlavor).
+				WithRangeFilter("age", 18, 30, true, true).
+				WithRangeFilter("age", 18, 30, tt.lowInclusive, tt.highInclusive)
+			sqlQuery, args := FindQuery("players", options)
+			assert.Equal(t, tt.expectedSQL, sqlQuery)
+			assert.Equal(t, []interface{}{18, 30}, args)
+		})
+	}
+}
+
+func TestEmptyIn(t *testing.T) {
+	var tests = []struct {
+		kind         string
+		behavior     EmptyInBehavior
+		key          string
+		value        interface{}
+		expectedSQL  string
+		expectedArgs []interface{}
+	}{
+		{"in empty match nothing", EmptyInMatchNothing, "id.in", "", `SELECT * FROM players WHERE 1 = 0`, []interface{}(nil)},
+		{"in whitespace match nothing", EmptyInMatchNothing, "id.in", "  ", `SELECT * FROM players WHERE 1 = 0`, []interface{}(nil)},
+		{"notin empty match nothing", EmptyInMatchNothing, "id.notin", "", `SELECT * FROM players`, []interface{}(nil)},
+		{"in empty skip", EmptyInSkip, "id.in", "", `SELECT * FROM players`, []interface{}(nil)},
+		{"notin empty skip", EmptyInSkip, "id.notin", " ", `SELECT * FROM players`, []interface{}(nil)},
+		{"in single element", EmptyInMatchNothing, "id.in", "1", `SELECT * FROM players WHERE id IN ($1)`, []interface{}{"1"}},
+		{"notin single element", EmptyInSkip, "id.notin", "1", `SELECT * FROM players WHERE id NOT IN ($1)`, []interface{}{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			options := NewFindOptions(PostgreSQLFlavor).WithEmptyIn(tt.behavior).WithFilter(tt.key, tt.value)
+			sqlQuery, args := FindQuery("players", options)
+			assert.Equal(t, tt.expectedSQL, sqlQuery)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	t.Run("FindQuery", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithFilter("id", 1).WithSoftDelete("deleted_at")
+		sqlQuery, args := FindQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE id = $1 AND deleted_at IS NULL`, sqlQuery)
+		assert.Equal(t, []interface{}{1}, args)
+	})
+
+	t.Run("FindAllQuery", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("id", 1).WithLimit(10).WithSoftDelete("deleted_at")
+		sqlQuery, args := FindAllQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE id = $1 AND deleted_at IS NULL LIMIT 10 OFFSET 0`, sqlQuery)
+		assert.Equal(t, []interface{}{1}, args)
+	})
+
+	t.Run("WithIncludeDeleted", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("id", 1).WithLimit(10).WithSoftDelete("deleted_at").WithIncludeDeleted()
+		sqlQuery, args := FindAllQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE id = $1 LIMIT 10 OFFSET 0`, sqlQuery)
+		assert.Equal(t, []interface{}{1}, args)
+	})
+}
+
+func TestLockWait(t *testing.T) {
+	var tests = []struct {
+		kind        string
+		flavor      Flavor
+		expectedSQL string
+	}{
+		{"mariadb", MariaDBFlavor, "SELECT * FROM jobs WHERE id = ? FOR UPDATE WAIT 5"},
+		{"mysql", MySQLFlavor, "SELECT * FROM jobs WHERE id = ? FOR UPDATE"},
+		{"postgresql", PostgreSQLFlavor, "SELECT * FROM jobs WHERE id = $1 FOR UPDATE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			options := NewFindOptions(tt.flavor).WithFilter("id", 1).WithForUpdate("").WithLockWait(5)
+			sqlQuery, args := FindQuery("jobs", options)
+			assert.Equal(t, tt.expectedSQL, sqlQuery)
+			assert.Equal(t, []interface{}{1}, args)
+		})
+	}
+
+	t.Run("share", func(t *testing.T) {
+		sqlQuery, _ := FindQuery("jobs", NewFindOptions(MariaDBFlavor).WithFilter("id", 1).WithForShare("").WithLockWait(5))
+		assert.Equal(t, "SELECT * FROM jobs WHERE id = ? LOCK IN SHARE MODE WAIT 5", sqlQuery)
+		sqlQuery, _ = FindQuery("jobs", NewFindOptions(MySQLFlavor).WithFilter("id", 1).WithForShare("").WithLockWait(5))
+		assert.Equal(t, "SELECT * FROM jobs WHERE id = ? LOCK IN SHARE MODE", sqlQuery)
+	})
+}
+
+func TestSoftDeleteQuery(t *testing.T) {
+	deletedAt := time.Date(2024, 2, 15, 10, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		kind        string
+		flavor      Flavor
+		expectedSQL string
+	}{
+		{"mysql", MySQLFlavor, "UPDATE players SET deleted_at = ? WHERE id = ?"},
+		{"postgresql", PostgreSQLFlavor, "UPDATE players SET deleted_at = $1 WHERE id = $2"},
+		{"sqlite", SQLiteFlavor, "UPDATE players SET deleted_at = ? WHERE id = ?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			sqlQuery, args := SoftDeleteQuery(tt.flavor, "players", "deleted_at", 1, WithDeletedAt(deletedAt))
+			assert.Equal(t, tt.expectedSQL, sqlQuery)
+			assert.Equal(t, []interface{}{deletedAt, 1}, args)
+		})
+	}
+
+	t.Run("current time", func(t *testing.T) {
+		_, args := SoftDeleteQuery(PostgreSQLFlavor, "players", "deleted_at", 1)
+		assert.Len(t, args, 2)
+		assert.IsType(t, time.Time{}, args[0])
+		assert.WithinDuration(t, time.Now(), args[0].(time.Time), time.Minute)
+	})
+}
+
+func TestBlankFilter(t *testing.T) {
+	options := NewDeleteOptions(PostgreSQLFlavor).WithBlankFilter("nickname").WithFilter("team_id", 1)
+	sqlQuery, args := DeleteWithOptionsQuery("players", options)
+	assert.Equal(t, `DELETE FROM players WHERE (nickname IS NULL OR nickname = '') AND team_id = $1`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestUpsertQuery(t *testing.T) {
+	r10 := player{ID: 1, Name: "Ronaldinho 10"}
+
+	t.Run("postgresql", func(t *testing.T) {
+		options := NewUpsertOptions(PostgreSQLFlavor).WithConflictColumns("id").WithReturning("id", "name")
+		sqlQuery, args, err := UpsertQuery("insert", "players", &r10, options)
+		assert.NoError(t, err)
+		assert.Equal(t, `INSERT INTO players (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name RETURNING id, name`, sqlQuery)
+		assert.Equal(t, []interface{}{1, "Ronaldinho 10"}, args)
+	})
+
+	t.Run("postgresql without conflict columns", func(t *testing.T) {
+		_, _, err := UpsertQuery("insert", "players", &r10, NewUpsertOptions(PostgreSQLFlavor))
+		assert.ErrorIs(t, err, ErrMissingConflictColumns)
+	})
+
+	t.Run("postgresql conflict constraint", func(t *testing.T) {
+		options := NewUpsertOptions(PostgreSQLFlavor).WithConflictConstraint("players_name_key").WithUpdateColumns("name")
+		sqlQuery, args, err := UpsertQuery("insert", "players", &r10, options)
+		assert.NoError(t, err)
+		assert.Equal(t, `INSERT INTO players (id, name) VALUES ($1, $2) ON CONFLICT ON CONSTRAINT players_name_key DO UPDATE SET name = EXCLUDED.name`, sqlQuery)
+		assert.Equal(t, []interface{}{1, "Ronaldinho 10"}, args)
+
+		_, _, err = UpsertQuery("insert", "players", &r10, NewUpsertOptions(SQLiteFlavor).WithConflictConstraint("players_name_key"))
+		assert.ErrorIs(t, err, ErrMissingConflictColumns)
+	})
+
+	t.Run("postgresql conflict where", func(t *testing.T) {
+		options := NewUpsertOptions(PostgreSQLFlavor).WithConflictColumns("name").WithConflictWhere("deleted_at IS NULL").WithUpdateColumns("id")
+		sqlQuery, args, err := UpsertQuery("insert", "players", &r10, options)
+		assert.NoError(t, err)
+		assert.Equal(t, `INSERT INTO players (id, name) VALUES ($1, $2) ON CONFLICT (name) WHERE deleted_at IS NULL DO UPDATE SET id = EXCLUDED.id`, sqlQuery)
+		assert.Equal(t, []interface{}{1, "Ronaldinho 10"}, args)
+
+		options = NewUpsertOptions(SQLiteFlavor).WithConflictColumns("name").WithConflictWhere("deleted_at IS NULL").WithUpdateColumns("id")
+		sqlQuery, _, err = UpsertQuery("insert", "players", &r10, options)
+		assert.NoError(t, err)
+		assert.Equal(t, `INSERT INTO players (id, name) VALUES (?, ?) ON CONFLICT (name) DO UPDATE SET id = EXCLUDED.id`, sqlQuery)
+
+		options = NewUpsertOptions(MySQLFlavor).WithConflictWhere("deleted_at IS NULL").WithUpdateColumns("name")
+		sqlQuery, _, err = UpsertQuery("insert", "players", &r10, options)
+		assert.NoError(t, err)
+		assert.Equal(t, `INSERT INTO players (id, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)`, sqlQuery)
+	})
+
+	t.Run("mariadb returning", func(t *testing.T) {
+		options := NewUpsertOptions(MariaDBFlavor).WithUpdateColumns("name").WithReturning("id")
+		sqlQuery, args, err := UpsertQuery("insert", "players", &r10, options)
+		assert.NoError(t, err)
+		assert.Equal(t, `INSERT INTO players (id, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name) RETURNING id`, sqlQuery)
+		assert.Equal(t, []interface{}{1, "Ronaldinho 10"}, args)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		options := NewUpsertOptions(MySQLFlavor).WithUpdateColumns("name")
+		sqlQuery, _, err := UpsertQuery("insert", "players", &r10, options)
+		assert.NoError(t, err)
+		assert.Equal(t, `INSERT INTO players (id, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)`, sqlQuery)
+	})
+
+	t.Run("mysql returning", func(t *testing.T) {
+		options := NewUpsertOptions(MySQLFlavor).WithReturning("id")
+		_, _, err := UpsertQuery("insert", "players", &r10, options)
+		assert.ErrorIs(t, err, ErrReturningNotSupported)
+	})
+}
+
+func TestSchemaQualifiedTableName(t *testing.T) {
+	var tests = []struct {
+		kind        string
+		flavor      Flavor
+		expectedSQL string
+	}{
+		{"mysql", MySQLFlavor, "SELECT * FROM `analytics`.`events` WHERE id = ?"},
+		{"mariadb", MariaDBFlavor, "SELECT * FROM `analytics`.`events` WHERE id = ?"},
+		{"postgresql", PostgreSQLFlavor, `SELECT * FROM "analytics"."events" WHERE id = $1`},
+		{"sqlite", SQLiteFlavor, `SELECT * FROM "analytics"."events" WHERE id = ?`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			sqlQuery, _ := FindQuery("analytics.events", NewFindOptions(tt.flavor).WithFilter("id", 1))
+			assert.Equal(t, tt.expectedSQL, sqlQuery)
+
+			sqlQuery, _ = FindQuery("events", NewFindOptions(tt.flavor).WithFilter("id", 1).WithSchema("analytics"))
+			assert.Equal(t, tt.expectedSQL, sqlQuery)
+		})
+	}
+
+	t.Run("DeleteWithOptionsQuery", func(t *testing.T) {
+		sqlQuery, _ := DeleteWithOptionsQuery("events", NewDeleteOptions(PostgreSQLFlavor).WithSchema("analytics").WithFilter("id", 1))
+		assert.Equal(t, `DELETE FROM "analytics"."events" WHERE id = $1`, sqlQuery)
+	})
+
+	t.Run("InsertQuery", func(t *testing.T) {
+		sqlQuery, _ := InsertQuery(PostgreSQLFlavor, "insert", "fifa.players", &player{ID: 1, Name: "R10"})
+		assert.Equal(t, `INSERT INTO "fifa"."players" (id, name) VALUES ($1, $2)`, sqlQuery)
+	})
+}
+
+func TestEscapeLike(t *testing.T) {
+	var tests = []struct {
+		kind     string
+		input    string
+		expected string
+	}{
+		{"no metacharacters", "ronaldinho", "ronaldinho"},
+		{"percent", "50%", `50\%`},
+		{"underscore", "r_10", `r\_10`},
+		{"escape character", `c:\dir`, `c:\\dir`},
+		{"all metacharacters", `\%_`, `\\\%\_`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EscapeLike(tt.input))
+		})
+	}
+}
+
+func TestStartsWithAny(t *testing.T) {
+	t.Run("postgresql", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithStartsWithAny("path", "/api", "/v1_")
+		sqlQuery, args := FindQuery("routes", options)
+		assert.Equal(t, `SELECT * FROM routes WHERE (path LIKE $1 OR path LIKE $2)`, sqlQuery)
+		assert.Equal(t, []interface{}{"/api%", `/v1\_%`}, args)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		options := NewFindOptions(SQLiteFlavor).WithStartsWithAny("path", "/api", "/v1")
+		sqlQuery, args := FindQuery("routes", options)
+		assert.Equal(t, `SELECT * FROM routes WHERE (path LIKE ? ESCAPE '\' OR path LIKE ? ESCAPE '\')`, sqlQuery)
+		assert.Equal(t, []interface{}{"/api%", "/v1%"}, args)
+	})
+
+	t.Run("no prefixes", func(t *testing.T) {
+		sqlQuery, _ := FindQuery("routes", NewFindOptions(PostgreSQLFlavor).WithStartsWithAny("path"))
+		assert.Equal(t, `SELECT * FROM routes WHERE 1 = 0`, sqlQuery)
+	})
+}
+
+func TestCoveringColumns(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"*"}).
+		WithCoveringColumns("team_id", "name").
+		WithFilter("team_id", 1).
+		WithLimit(10)
+	sqlQuery, args := FindAllQuery("players", options)
+	assert.Equal(t, `SELECT team_id, name FROM players WHERE team_id = $1 LIMIT 10 OFFSET 0`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestFloatApproxFilter(t *testing.T) {
+	options := NewFindOptions(PostgreSQLFlavor).WithFloatApproxFilter("rating", 9.5, 0.01)
+	sqlQuery, args := FindQuery("players", options)
+	assert.Equal(t, `SELECT * FROM players WHERE ABS(rating - $1) < $2`, sqlQuery)
+	assert.Equal(t, []interface{}{9.5, 0.01}, args)
+}
+
+func TestFindAllCursorQuery(t *testing.T) {
+	var tests = []struct {
+		kind         string
+		lastValue    interface{}
+		direction    string
+		expectedSQL  string
+		expectedArgs []interface{}
+	}{
+		{"asc", 10, "asc", `SELECT * FROM players WHERE team_id = $1 AND id > $2 ORDER BY id ASC LIMIT 20`, []interface{}{1, 10}},
+		{"desc", 10, "DESC", `SELECT * FROM players WHERE team_id = $1 AND id < $2 ORDER BY id DESC LIMIT 20`, []interface{}{1, 10}},
+		{"first page", nil, "desc", `SELECT * FROM players WHERE team_id = $1 ORDER BY id DESC LIMIT 20`, []interface{}{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			options := NewFindAllOptions(PostgreSQLFlavor).
+				WithFilter("team_id", 1).
+				WithLimit(20).
+				WithOffset(40).
+				WithOrderBy("name asc").
+				WithCursor("id", tt.lastValue, tt.direction)
+			sqlQuery, args, err := FindAllCursorQueryE("players", options)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSQL, sqlQuery)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+
+	t.Run("invalid direction", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).WithLimit(20).WithCursor("id", 10, "up")
+		_, _, err := FindAllCursorQueryE("players", options)
+		assert.ErrorIs(t, err, ErrInvalidDirection)
+	})
+}
+
+func TestWithField(t *testing.T) {
+	t.Run("with star", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithField("COALESCE(nickname, name)", "display_name").WithFilter("id", 1)
+		sqlQuery, args := FindQuery("players", options)
+		assert.Equal(t, `SELECT *, COALESCE(nickname, name) AS "display_name" FROM players WHERE id = $1`, sqlQuery)
+		assert.Equal(t, []interface{}{1}, args)
+	})
+
+	t.Run("with plain columns", func(t *testing.T) {
+		options := NewFindOptions(MySQLFlavor).WithFields([]string{"id"}).WithField("COALESCE(nickname, name)", "display_name")
+		sqlQuery, _ := FindQuery("players", options)
+		assert.Equal(t, "SELECT id, COALESCE(nickname, name) AS `display_name` FROM players", sqlQuery)
+	})
+}
+
+func TestRecursiveCTE(t *testing.T) {
+	expectedSQLQuery := `WITH RECURSIVE tree AS (SELECT id, parent_id FROM categories WHERE id = $1 UNION ALL SELECT c.id, c.parent_id FROM categories c JOIN tree t ON c.parent_id = t.id AND c.depth < $2) SELECT * FROM tree WHERE id <> $3 LIMIT 10 OFFSET 0`
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithRecursiveCTE(
+			"tree",
+			"SELECT id, parent_id FROM categories WHERE id = $?",
+			"SELECT c.id, c.parent_id FROM categories c JOIN tree t ON c.parent_id = t.id AND c.depth < $?",
+			1, 5,
+		).
+		WithFilter("id.not", 1).
+		WithLimit(10)
+	sqlQuery, args := FindAllQuery("tree", options)
+	assert.Equal(t, expectedSQLQuery, sqlQuery)
+	assert.Equal(t, []interface{}{1, 5, 1}, args)
+}
+
+func TestFindAllQueryLimitOffset(t *testing.T) {
+	var tests = []struct {
+		kind        string
+		limit       int
+		offset      int
+		expectedSQL string
+		expectedErr error
+	}{
+		{"zero limit", 0, 0, `SELECT * FROM players`, nil},
+		{"zero limit with offset", 0, 10, `SELECT * FROM players OFFSET 10`, nil},
+		{"negative limit", -1, 10, `SELECT * FROM players OFFSET 10`, ErrInvalidLimit},
+		{"negative offset", 10, -1, `SELECT * FROM players LIMIT 10 OFFSET 0`, ErrInvalidOffset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			options := NewFindAllOptions(PostgreSQLFlavor).WithLimit(tt.limit).WithOffset(tt.offset)
+			sqlQuery, args := FindAllQuery("players", options)
+			assert.Equal(t, tt.expectedSQL, sqlQuery)
+			assert.Equal(t, []interface{}(nil), args)
+
+			_, _, err := FindAllQueryE("players", options)
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestTypedIn(t *testing.T) {
+	t.Run("int", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithTypedIn("id", "1, 2,3", "int")
+		sqlQuery, args, err := FindQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM players WHERE id IN ($1, $2, $3)`, sqlQuery)
+		assert.Equal(t, []interface{}{1, 2, 3}, args)
+	})
+
+	t.Run("time", func(t *testing.T) {
+		options := NewDeleteOptions(PostgreSQLFlavor).WithTypedIn("created_at", "2024-01-01T00:00:00Z,2024-01-02T10:30:00Z", "time")
+		sqlQuery, args, err := DeleteWithOptionsQueryE("events", options)
+		assert.NoError(t, err)
+		assert.Equal(t, `DELETE FROM events WHERE created_at IN ($1, $2)`, sqlQuery)
+		assert.Equal(t, []interface{}{
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC),
+		}, args)
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithTypedIn("id", "1,two", "int")
+		_, _, err := FindQueryE("players", options)
+		assert.ErrorIs(t, err, ErrInvalidValue)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithTypedIn("id", "1", "uint8")
+		_, _, err := FindQueryE("players", options)
+		assert.ErrorIs(t, err, ErrInvalidValue)
+	})
+}
+
+func TestForShare(t *testing.T) {
+	var tests = []struct {
+		kind        string
+		flavor      Flavor
+		mode        string
+		expectedSQL string
+	}{
+		{"mysql", MySQLFlavor, "", "SELECT * FROM players WHERE id = ? LIMIT 1 OFFSET 0 LOCK IN SHARE MODE"},
+		{"mysql nowait", MySQLFlavor, "NOWAIT", "SELECT * FROM players WHERE id = ? LIMIT 1 OFFSET 0 FOR SHARE NOWAIT"},
+		{"mysql skip locked", MySQLFlavor, "SKIP LOCKED", "SELECT * FROM players WHERE id = ? LIMIT 1 OFFSET 0 FOR SHARE SKIP LOCKED"},
+		{"mariadb nowait", MariaDBFlavor, "NOWAIT", "SELECT * FROM players WHERE id = ? LIMIT 1 OFFSET 0 LOCK IN SHARE MODE NOWAIT"},
+		{"postgresql", PostgreSQLFlavor, "", "SELECT * FROM players WHERE id = $1 LIMIT 1 OFFSET 0 FOR SHARE"},
+		{"postgresql nowait", PostgreSQLFlavor, "NOWAIT", "SELECT * FROM players WHERE id = $1 LIMIT 1 OFFSET 0 FOR SHARE NOWAIT"},
+		{"sqlite", SQLiteFlavor, "", "SELECT * FROM players WHERE id = ? LIMIT 1 OFFSET 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			options := NewFindAllOptions(tt.flavor).WithFilter("id", 1).WithLimit(1).WithForShare(tt.mode)
+			sqlQuery, args := FindAllQuery("players", options)
+			assert.Equal(t, tt.expectedSQL, sqlQuery)
+			assert.Equal(t, []interface{}{1}, args)
+		})
+	}
+
+	t.Run("sqlite for update", func(t *testing.T) {
+		sqlQuery, _ := FindQuery("players", NewFindOptions(SQLiteFlavor).WithFilter("id", 1).WithForUpdate("NOWAIT"))
+		assert.Equal(t, "SELECT * FROM players WHERE id = ?", sqlQuery)
+	})
+
+	t.Run("mutually exclusive", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithForUpdate("SKIP LOCKED").WithForShare("")
+		assert.True(t, options.ForShare)
+		assert.False(t, options.ForUpdate)
+		sqlQuery, _ := FindQuery("players", options)
+		assert.Equal(t, "SELECT * FROM players FOR SHARE", sqlQuery)
+
+		options = options.WithForUpdate("NOWAIT")
+		assert.False(t, options.ForShare)
+		assert.True(t, options.ForUpdate)
+		sqlQuery, _ = FindQuery("players", options)
+		assert.Equal(t, "SELECT * FROM players FOR UPDATE NOWAIT", sqlQuery)
+	})
+}
+
+func TestTopNPerGroupQuery(t *testing.T) {
+	expectedSQLQuery := `SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY category_id ORDER BY price DESC) AS rn FROM products WHERE active = $1) AS ranked WHERE rn <= $2 ORDER BY category_id, rn`
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("active", true).WithOrderBy("category_id, rn")
+	sqlQuery, args := TopNPerGroupQuery("products", "category_id", "price DESC", 3, options)
+	assert.Equal(t, expectedSQLQuery, sqlQuery)
+	assert.Equal(t, []interface{}{true, 3}, args)
+}
+
+func TestTruncateQuery(t *testing.T) {
+	tests := []struct {
+		flavor   Flavor
+		options  []TruncateOption
+		expected string
+	}{
+		{MySQLFlavor, nil, "TRUNCATE TABLE players"},
+		{MySQLFlavor, []TruncateOption{WithRestartIdentity()}, "TRUNCATE TABLE players"},
+		{PostgreSQLFlavor, nil, "TRUNCATE TABLE players"},
+		{PostgreSQLFlavor, []TruncateOption{WithRestartIdentity()}, "TRUNCATE TABLE players RESTART IDENTITY"},
+		{SQLiteFlavor, []TruncateOption{WithRestartIdentity()}, "DELETE FROM players"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, TruncateQuery(tt.flavor, "players", tt.options...))
+	}
+}
+
+func TestLikeAnyArray(t *testing.T) {
+	t.Run("postgresql", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithFilter("active", true).WithLikeAnyArray("email", []string{"%@example.com", "admin%"})
+		sqlQuery, args := FindQuery("users", options)
+		assert.Equal(t, `SELECT * FROM users WHERE active = $1 AND email LIKE ANY(ARRAY[$2, $3])`, sqlQuery)
+		assert.Equal(t, []interface{}{true, "%@example.com", "admin%"}, args)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		options := NewFindOptions(MySQLFlavor).WithLikeAnyArray("email", []string{"%@example.com", "admin%"})
+		sqlQuery, args := FindQuery("users", options)
+		assert.Equal(t, `SELECT * FROM users WHERE (email LIKE ? OR email LIKE ?)`, sqlQuery)
+		assert.Equal(t, []interface{}{"%@example.com", "admin%"}, args)
+	})
+
+	t.Run("no patterns", func(t *testing.T) {
+		sqlQuery, _ := FindQuery("users", NewFindOptions(PostgreSQLFlavor).WithLikeAnyArray("email", nil))
+		assert.Equal(t, `SELECT * FROM users WHERE 1 = 0`, sqlQuery)
+	})
+}
+
+func TestUpdateReturning(t *testing.T) {
+	t.Run("postgresql", func(t *testing.T) {
+		options := NewUpdateOptions(PostgreSQLFlavor).WithAssignment("name", "Ronaldinho").WithFilter("id", 1).WithReturning("id", "updated_at")
+		sqlQuery, args, err := UpdateWithOptionsQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, `UPDATE players SET name = $1 WHERE id = $2 RETURNING id, updated_at`, sqlQuery)
+		assert.Equal(t, []interface{}{"Ronaldinho", 1}, args)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		options := NewUpdateOptions(SQLiteFlavor).WithAssignment("name", "Ronaldinho").WithReturning("id")
+		sqlQuery, args := UpdateWithOptionsQuery("players", options)
+		assert.Equal(t, `UPDATE players SET name = ? RETURNING id`, sqlQuery)
+		assert.Equal(t, []interface{}{"Ronaldinho"}, args)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		options := NewUpdateOptions(MySQLFlavor).WithAssignment("name", "Ronaldinho").WithFilter("id", 1).WithReturning("id")
+		sqlQuery, _ := UpdateWithOptionsQuery("players", options)
+		assert.Equal(t, "UPDATE players SET name = ? WHERE id = ?", sqlQuery)
+		_, _, err := UpdateWithOptionsQueryE("players", options)
+		assert.ErrorIs(t, err, ErrReturningNotSupported)
+	})
+}
+
+func TestTieBreaker(t *testing.T) {
+	tests := []struct {
+		orderBy  string
+		expected string
+	}{
+		{"", `SELECT * FROM players ORDER BY id LIMIT 10 OFFSET 0`},
+		{"name asc", `SELECT * FROM players ORDER BY name asc, id LIMIT 10 OFFSET 0`},
+		{"name asc, id desc", `SELECT * FROM players ORDER BY name asc, id desc LIMIT 10 OFFSET 0`},
+		{"ID", `SELECT * FROM players ORDER BY ID LIMIT 10 OFFSET 0`},
+	}
+	for _, tt := range tests {
+		options := NewFindAllOptions(PostgreSQLFlavor).WithOrderBy(tt.orderBy).WithTieBreaker("id").WithLimit(10)
+		sqlQuery, _ := FindAllQuery("players", options)
+		assert.Equal(t, tt.expected, sqlQuery)
+	}
+}
+
+func TestDeleteReturning(t *testing.T) {
+	tests := []struct {
+		flavor   Flavor
+		expected string
+		args     []interface{}
+		err      error
+	}{
+		{PostgreSQLFlavor, `DELETE FROM players WHERE id IN ($1, $2) AND name = $3 RETURNING id`, []interface{}{"1", "2", "Ronaldinho"}, nil},
+		{SQLiteFlavor, `DELETE FROM players WHERE id IN (?, ?) AND name = ? RETURNING id`, []interface{}{"1", "2", "Ronaldinho"}, nil},
+		{MariaDBFlavor, "DELETE FROM players WHERE id IN (?, ?) AND name = ? RETURNING id", []interface{}{"1", "2", "Ronaldinho"}, nil},
+		{MySQLFlavor, "", nil, ErrReturningNotSupported},
+	}
+	for _, tt := range tests {
+		options := NewDeleteOptions(tt.flavor).WithFilter("id.in", "1,2").WithFilter("name", "Ronaldinho").WithReturning("id")
+		sqlQuery, args, err := DeleteWithOptionsQueryE("players", options)
+		assert.ErrorIs(t, err, tt.err)
+		assert.Equal(t, tt.expected, sqlQuery)
+		assert.Equal(t, tt.args, args)
+	}
+
+	sqlQuery, _ := DeleteWithOptionsQuery("players", NewDeleteOptions(MySQLFlavor).WithFilter("id", 1).WithReturning("id"))
+	assert.Equal(t, "DELETE FROM players WHERE id = ?", sqlQuery)
+
+	options := NewDeleteOptions(MariaDBFlavor).
+		WithJoin("teams", "players.team_id = teams.id").
+		WithFilter("teams.name", "Barcelona").
+		WithReturning("id")
+	_, _, err := DeleteWithOptionsQueryE("players", options)
+	assert.ErrorIs(t, err, ErrReturningNotSupported)
+}
+
+func TestFindAllQueryNamed(t *testing.T) {
+	t.Run("postgresql", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).
+			WithFilter("age.gte", 18).
+			WithFilter("age.lte", 30).
+			WithFilter("id.in", "1,2").
+			WithFilter("name", "Ronaldinho").
+			WithLimit(10)
+		sqlQuery, args := FindAllQueryNamed("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE age >= :age_gte AND age <= :age_lte AND id IN (:id_in_1, :id_in_2) AND name = :name LIMIT 10 OFFSET 0`, sqlQuery)
+		assert.Equal(t, map[string]interface{}{"age_gte": 18, "age_lte": 30, "id_in_1": "1", "id_in_2": "2", "name": "Ronaldinho"}, args)
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		options := NewFindAllOptions(SQLiteFlavor).WithFilter("name.startswith", "Ron").WithFilter("team_id", 1)
+		sqlQuery, args := FindAllQueryNamed("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE name LIKE :name_startswith ESCAPE '\' AND team_id = :team_id`, sqlQuery)
+		assert.Equal(t, map[string]interface{}{"name_startswith": "Ron%", "team_id": 1}, args)
+	})
+
+	t.Run("values join and qualified keys", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).
+			WithTableAlias("p").
+			WithValuesJoin("v", []string{"id"}, [][]interface{}{{7}, {8}}).
+			WithFilter("flags.popcount.gte", 2).
+			WithFilter("teams.id.in", []int{1, 2}).
+			WithTextLikeFilter("name", "Ron")
+		sqlQuery, args := FindAllQueryNamed("players", options)
+		assert.Equal(t, `SELECT * FROM players AS p JOIN (VALUES (:v_1), (:v_2)) AS v (id) ON p.id = v.id WHERE bit_count(p.flags) >= :flags_popcount_gte AND teams.id IN (:teams_id_in_1, :teams_id_in_2) AND p.name::::text LIKE :arg_6`, sqlQuery)
+		assert.Equal(t, map[string]interface{}{"v_1": 7, "v_2": 8, "flags_popcount_gte": 2, "teams_id_in_1": 1, "teams_id_in_2": 2, "arg_6": "%Ron%"}, args)
+	})
+}
+
+// compileNamed compiles the ":name" placeholders of sqlQuery to "?" with the sqlx rules, "::" is an escaped colon.
+func compileNamed(sqlQuery string, args map[string]interface{}) (string, []interface{}) {
+	var buf strings.Builder
+	var values []interface{}
+	for i := 0; i < len(sqlQuery); i++ {
+		if sqlQuery[i] != ':' {
+			buf.WriteByte(sqlQuery[i])
+			continue
+		}
+		if i+1 < len(sqlQuery) && sqlQuery[i+1] == ':' {
+			buf.WriteByte(':')
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(sqlQuery) && (sqlQuery[j] == '_' || 'a' <= sqlQuery[j] && sqlQuery[j] <= 'z' || '0' <= sqlQuery[j] && sqlQuery[j] <= '9') {
+			j++
+		}
+		buf.WriteByte('?')
+		values = append(values, args[sqlQuery[i+1:j]])
+		i = j - 1
+	}
+	return buf.String(), values
+}
+
+func TestFindAllQueryNamedRoundTrip(t *testing.T) {
+	t.Run("colliding names", func(t *testing.T) {
+		options := NewFindAllOptions(MySQLFlavor).
+			WithFilter("id.gt", 1).
+			WithFilter("id_gt", 9).
+			WithFilter("id.in", "1,2").
+			WithFilter("id_in_1", 7)
+		sqlQuery, args := FindAllQueryNamed("players", options)
+		assert.Equal(t, "SELECT * FROM players WHERE id > :id_gt AND id IN (:id_in_1, :id_in_2) AND id_gt = :id_gt_2 AND id_in_1 = :id_in_1_2", sqlQuery)
+		compiled, values := compileNamed(sqlQuery, args)
+		expected, expectedArgs := FindAllQuery("players", options)
+		assert.Equal(t, expected, compiled)
+		assert.Equal(t, expectedArgs, values)
+	})
+
+	t.Run("casts", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).
+			WithValuesJoin("v", []string{"id::bigint"}, [][]interface{}{{7}, {8}}).
+			WithFilter("team_id", 1).
+			WithSeededRandomOrder("id", "seed")
+		sqlQuery, args := FindAllQueryNamed("players", options)
+		assert.Contains(t, sqlQuery, ":v_1::::bigint")
+		compiled, values := compileNamed(sqlQuery, args)
+		expected, expectedArgs := FindAllQuery("players", options)
+		assert.Equal(t, strings.Count(expected, "$"), strings.Count(compiled, "?"))
+		assert.Contains(t, compiled, "?::bigint")
+		assert.Contains(t, compiled, "id::text")
+		assert.Equal(t, expectedArgs, values)
+	})
+}
+
+func TestAntiJoinQuery(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"players.*"}).
+		WithFilter("team_id", 1).
+		WithFilter("name.like", "R%").
+		WithSoftDelete("deleted_at").
+		WithOrderBy("players.id").
+		WithLimit(10)
+	sqlQuery, args := AntiJoinQuery("players", "contracts", "contracts.player_id = players.id", "contracts.id", options)
+	assert.Equal(t, `SELECT players.* FROM players LEFT JOIN contracts ON contracts.player_id = players.id WHERE players.name LIKE $1 AND players.team_id = $2 AND players.deleted_at IS NULL AND contracts.id IS NULL ORDER BY players.id LIMIT 10 OFFSET 0`, sqlQuery)
+	assert.Equal(t, []interface{}{"R%", 1}, args)
+}
+
+func TestUUIDFilter(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithUUIDFilter("id", "7B0E1F3C-5D2A-4B8E-9C6F-1A2B3C4D5E6F")
+		sqlQuery, args, err := FindQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM players WHERE id = $1`, sqlQuery)
+		assert.Equal(t, []interface{}{"7B0E1F3C-5D2A-4B8E-9C6F-1A2B3C4D5E6F"}, args)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, value := range []string{"", "7b0e1f3c5d2a4b8e9c6f1a2b3c4d5e6f", "7b0e1f3c-5d2a-4b8e-9c6f-1a2b3c4d5e6g", "1 OR 1=1"} {
+			_, _, err := DeleteWithOptionsQueryE("players", NewDeleteOptions(PostgreSQLFlavor).WithUUIDFilter("id", value))
+			assert.ErrorIs(t, err, ErrInvalidValue)
+		}
+	})
+}
+
+func TestNotGroup(t *testing.T) {
+	t.Run("postgresql", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).
+			WithFilter("team_id", 1).
+			WithNotGroup(map[string]interface{}{"name": "Ronaldinho", "age.gte": 40}).
+			WithLimit(10)
+		sqlQuery, args := FindAllQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 AND NOT (age >= $2 AND name = $3) LIMIT 10 OFFSET 0`, sqlQuery)
+		assert.Equal(t, []interface{}{1, 40, "Ronaldinho"}, args)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		options := NewDeleteOptions(MySQLFlavor).WithNotGroup(map[string]interface{}{"id.in": "1,2", "active": true})
+		sqlQuery, args := DeleteWithOptionsQuery("players", options)
+		assert.Equal(t, "DELETE FROM players WHERE NOT (active = ? AND id IN (?, ?))", sqlQuery)
+		assert.Equal(t, []interface{}{true, "1", "2"}, args)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithNotGroup(map[string]interface{}{"id.unknown": 1})
+		sqlQuery, _ := FindQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players`, sqlQuery)
+		_, _, err := FindQueryE("players", options)
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+	})
+}
+
+func TestInsertDefaultValuesQuery(t *testing.T) {
+	tests := []struct {
+		flavor   Flavor
+		expected string
+	}{
+		{MySQLFlavor, "INSERT INTO events () VALUES ()"},
+		{MariaDBFlavor, "INSERT INTO events () VALUES ()"},
+		{PostgreSQLFlavor, "INSERT INTO events DEFAULT VALUES"},
+		{SQLiteFlavor, "INSERT INTO events DEFAULT VALUES"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, InsertDefaultValuesQuery(tt.flavor, "events"))
+	}
+}
+
+func TestArrayOperators(t *testing.T) {
+	t.Run("postgresql", func(t *testing.T) {
+		tests := []struct {
+			key      string
+			value    interface{}
+			expected string
+			arg      string
+		}{
+			{"tags.arraycontains", "go,sql", `SELECT * FROM posts WHERE tags @> $1`, "{go,sql}"},
+			{"tags.arraycontainedby", []string{"go", "sql"}, `SELECT * FROM posts WHERE tags <@ $1`, "{go,sql}"},
+			{"tags.arrayoverlap", []string{"a,b", `c"d`, ""}, `SELECT * FROM posts WHERE tags && $1`, `{"a,b","c\"d",""}`},
+		}
+		for _, tt := range tests {
+			sqlQuery, args, err := FindQueryE("posts", NewFindOptions(PostgreSQLFlavor).WithFilter(tt.key, tt.value))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, sqlQuery)
+			assert.Equal(t, []interface{}{tt.arg}, args)
+		}
+	})
+
+	t.Run("unsupported flavors", func(t *testing.T) {
+		for _, flavor := range []Flavor{MySQLFlavor, SQLiteFlavor} {
+			options := NewFindOptions(flavor).WithFilter("tags.arraycontains", "go")
+			sqlQuery, _ := FindQuery("posts", options)
+			assert.Equal(t, "SELECT * FROM posts", sqlQuery)
+			_, _, err := FindQueryE("posts", options)
+			assert.ErrorIs(t, err, ErrUnsupportedOperator)
+		}
+	})
+}
+
+func TestFindInSet(t *testing.T) {
+	options := NewFindOptions(MySQLFlavor).WithFilter("team_id", 1).WithFilter("roles.findinset", "admin")
+	sqlQuery, args := FindQuery("users", options)
+	assert.Equal(t, "SELECT * FROM users WHERE FIND_IN_SET(?, roles) > 0 AND team_id = ?", sqlQuery)
+	assert.Equal(t, []interface{}{"admin", 1}, args)
+
+	_, _, err := FindQueryE("users", NewFindOptions(PostgreSQLFlavor).WithFilter("roles.findinset", "admin"))
+	assert.ErrorIs(t, err, ErrUnsupportedOperator)
+}
+
+func TestJSONFilter(t *testing.T) {
+	tests := []struct {
+		flavor       Flavor
+		path         string
+		expected     string
+		expectedArgs []interface{}
+	}{
+		{PostgreSQLFlavor, "status", `SELECT * FROM orders WHERE customer_id = $1 AND data#>>$2::text[] = $3`, []interface{}{1, "{status}", "active"}},
+		{PostgreSQLFlavor, "shipping.status", `SELECT * FROM orders WHERE customer_id = $1 AND data#>>$2::text[] = $3`, []interface{}{1, "{shipping,status}", "active"}},
+		{PostgreSQLFlavor, `a,b}.it's "x"`, `SELECT * FROM orders WHERE customer_id = $1 AND data#>>$2::text[] = $3`, []interface{}{1, `{"a,b}","it's \"x\""}`, "active"}},
+		{PostgreSQLFlavor, "tab\t.new\nline", `SELECT * FROM orders WHERE customer_id = $1 AND data#>>$2::text[] = $3`, []interface{}{1, "{\"tab\t\",\"new\nline\"}", "active"}},
+		{MySQLFlavor, "status", "SELECT * FROM orders WHERE customer_id = ? AND JSON_EXTRACT(data, ?) = ?", []interface{}{1, "$.status", "active"}},
+		{MySQLFlavor, "shipping.status", "SELECT * FROM orders WHERE customer_id = ? AND JSON_EXTRACT(data, ?) = ?", []interface{}{1, "$.shipping.status", "active"}},
+		{MySQLFlavor, `x\' OR 1=1 -- `, "SELECT * FROM orders WHERE customer_id = ? AND JSON_EXTRACT(data, ?) = ?", []interface{}{1, `$.x\' OR 1=1 -- `, "active"}},
+		{SQLiteFlavor, "status", "SELECT * FROM orders WHERE customer_id = ? AND json_extract(data, ?) = ?", []interface{}{1, "$.status", "active"}},
+		{SQLiteFlavor, "it's", "SELECT * FROM orders WHERE customer_id = ? AND json_extract(data, ?) = ?", []interface{}{1, "$.it's", "active"}},
+	}
+	for _, tt := range tests {
+		options := NewFindOptions(tt.flavor).WithFilter("customer_id", 1).WithJSONFilter("data", tt.path, "active")
+		sqlQuery, args := FindQuery("orders", options)
+		assert.Equal(t, tt.expected, sqlQuery)
+		assert.Equal(t, tt.expectedArgs, args)
+	}
+
+	sqlQuery, args := FindQuery("orders", NewFindOptions(PostgreSQLFlavor).WithJSONFilter("data", "status", nil))
+	assert.Equal(t, `SELECT * FROM orders WHERE data#>>$1::text[] IS NULL`, sqlQuery)
+	assert.Equal(t, []interface{}{"{status}"}, args)
+	sqlQuery, args = FindQuery("orders", NewFindOptions(MySQLFlavor).WithJSONFilter("data", "status", nil))
+	assert.Equal(t, "SELECT * FROM orders WHERE JSON_EXTRACT(data, ?) IS NULL", sqlQuery)
+	assert.Equal(t, []interface{}{"$.status"}, args)
+}
+
+func TestInSubquery(t *testing.T) {
+	t.Run("postgresql", func(t *testing.T) {
+		sub := NewFindOptions(PostgreSQLFlavor).WithFields([]string{"id"}).WithFilter("active", true).WithFilter("country", "BR")
+		options := NewFindAllOptions(PostgreSQLFlavor).
+			WithFilter("age.gte", 18).
+			WithInSubquery("team_id", sub, "teams").
+			WithFilter("name.like", "R%").
+			WithLimit(10)
+		sqlQuery, args := FindAllQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE age >= $1 AND name LIKE $2 AND team_id IN (SELECT id FROM teams WHERE active = $3 AND country = $4) LIMIT 10 OFFSET 0`, sqlQuery)
+		assert.Equal(t, []interface{}{18, "R%", true, "BR"}, args)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		sub := NewFindOptions(PostgreSQLFlavor).WithFields([]string{"id"}).WithFilter("active", false)
+		options := NewUpdateOptions(PostgreSQLFlavor).WithAssignment("active", false).WithInSubquery("team_id", sub, "teams")
+		sqlQuery, args := UpdateWithOptionsQuery("players", options)
+		assert.Equal(t, `UPDATE players SET active = $1 WHERE team_id IN (SELECT id FROM teams WHERE active = $2)`, sqlQuery)
+		assert.Equal(t, []interface{}{false, false}, args)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		sub := NewFindOptions(PostgreSQLFlavor).WithFields([]string{"id"}).WithFilter("id.unknown", 1)
+		_, _, err := FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithInSubquery("team_id", sub, "teams"))
+		assert.ErrorIs(t, err, ErrUnknownOperator)
+	})
+}
+
+func TestFindAllPageQuery(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1).WithLimit(10).WithOffset(20)
+
+	t.Run("page with rows", func(t *testing.T) {
+		sqlQuery, args := FindAllPageQuery("players", options, 25)
+		assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 LIMIT 10 OFFSET 20`, sqlQuery)
+		assert.Equal(t, []interface{}{1}, args)
+	})
+
+	t.Run("offset beyond total", func(t *testing.T) {
+		for _, total := range []int{0, 20} {
+			sqlQuery, args := FindAllPageQuery("players", options, total)
+			assert.Equal(t, `SELECT * FROM players WHERE 1 = 0`, sqlQuery)
+			assert.Nil(t, args)
+		}
+	})
+
+	t.Run("offset beyond total with alias and joins", func(t *testing.T) {
+		options := options.
+			WithTableAlias("p").
+			WithFields([]string{"id", "teams.name"}).
+			WithJoin("teams", "teams.id = p.team_id").
+			WithSoftDelete("deleted_at").
+			WithOrderBy("id")
+		sqlQuery, args := FindAllPageQuery("players", options, 20)
+		assert.Equal(t, `SELECT p.id, teams.name FROM players AS p JOIN teams ON teams.id = p.team_id WHERE 1 = 0`, sqlQuery)
+		assert.Nil(t, args)
+	})
+}
+
+func TestNumericTextFilter(t *testing.T) {
+	tests := []struct {
+		flavor   Flavor
+		op       string
+		castType string
+		expected string
+	}{
+		{PostgreSQLFlavor, "gt", "integer", `SELECT * FROM products WHERE CAST(code AS INTEGER) > $1`},
+		{PostgreSQLFlavor, "", "NUMERIC", `SELECT * FROM products WHERE CAST(code AS NUMERIC) = $1`},
+		{MySQLFlavor, "lte", "SIGNED", "SELECT * FROM products WHERE CAST(code AS SIGNED) <= ?"},
+		{SQLiteFlavor, "not", "INTEGER", "SELECT * FROM products WHERE CAST(code AS INTEGER) <> ?"},
+	}
+	for _, tt := range tests {
+		options := NewFindOptions(tt.flavor).WithNumericTextFilter("code", tt.op, 100, tt.castType)
+		sqlQuery, args, err := FindQueryE("products", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, sqlQuery)
+		assert.Equal(t, []interface{}{100}, args)
+	}
+
+	_, _, err := FindQueryE("products", NewFindOptions(MySQLFlavor).WithNumericTextFilter("code", "gt", 100, "INTEGER"))
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	_, _, err = FindQueryE("products", NewFindOptions(PostgreSQLFlavor).WithNumericTextFilter("code", "INTEGER); DROP TABLE products; --", 100, "INTEGER"))
+	assert.ErrorIs(t, err, ErrUnknownOperator)
+	sqlQuery, _ := FindQuery("products", NewFindOptions(PostgreSQLFlavor).WithNumericTextFilter("code", "gt", 100, "TEXT"))
+	assert.Equal(t, `SELECT * FROM products`, sqlQuery)
+}
+
+func TestClaimQuery(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("status", "pending").WithOrderBy("created_at").WithTieBreaker("id")
+
+	t.Run("postgresql", func(t *testing.T) {
+		sqlQuery, args, err := ClaimQuery("jobs", options, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM jobs WHERE status = $1 ORDER BY created_at, id LIMIT 5 OFFSET 0 FOR UPDATE SKIP LOCKED`, sqlQuery)
+		assert.Equal(t, []interface{}{"pending"}, args)
+	})
+
+	t.Run("mariadb", func(t *testing.T) {
+		options := NewFindAllOptions(MariaDBFlavor).WithFilter("status", "pending").WithOrderBy("id").WithLockWait(5)
+		sqlQuery, _, err := ClaimQuery("jobs", options, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM jobs WHERE status = ? ORDER BY id LIMIT 5 OFFSET 0 FOR UPDATE SKIP LOCKED", sqlQuery)
+	})
+
+	t.Run("missing order by", func(t *testing.T) {
+		_, _, err := ClaimQuery("jobs", NewFindAllOptions(PostgreSQLFlavor), 5)
+		assert.ErrorIs(t, err, ErrMissingOrderBy)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, _, err := ClaimQuery("jobs", options, 0)
+		assert.ErrorIs(t, err, ErrInvalidLimit)
+	})
+}
+
+func TestJSONKey(t *testing.T) {
+	t.Run("mysql", func(t *testing.T) {
+		options := NewFindOptions(MySQLFlavor).
+			WithFilter("data.jsonkey", JSONKey{Key: "status", Value: "active"}).
+			WithFilter("id", 1)
+		sqlQuery, args := FindQuery("orders", options)
+		assert.Equal(t, "SELECT * FROM orders WHERE JSON_UNQUOTE(JSON_EXTRACT(data, ?)) = ? AND id = ?", sqlQuery)
+		assert.Equal(t, []interface{}{"$.status", "active", 1}, args)
+	})
+
+	t.Run("mysql key exists", func(t *testing.T) {
+		sqlQuery, args := FindQuery("orders", NewFindOptions(MySQLFlavor).WithFilter("data.jsonkey", "shipping.address"))
+		assert.Equal(t, "SELECT * FROM orders WHERE JSON_CONTAINS_PATH(data, 'one', ?)", sqlQuery)
+		assert.Equal(t, []interface{}{"$.shipping.address"}, args)
+	})
+
+	t.Run("mariadb key with quote", func(t *testing.T) {
+		sqlQuery, args, err := FindQueryE("orders", NewFindOptions(MariaDBFlavor).WithFilter("data.jsonkey", `x\' OR 1=1 -- `))
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM orders WHERE JSON_CONTAINS_PATH(data, 'one', ?)", sqlQuery)
+		assert.Equal(t, []interface{}{`$.x\' OR 1=1 -- `}, args)
+	})
+
+	t.Run("unsupported flavors", func(t *testing.T) {
+		_, _, err := FindQueryE("orders", NewFindOptions(PostgreSQLFlavor).WithFilter("data.jsonkey", "status"))
+		assert.ErrorIs(t, err, ErrUnsupportedOperator)
+	})
+}
+
+func TestAggregateQuery(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("status", "paid").WithLimit(10).WithOrderBy("id")
+	tests := []struct {
+		agg      Aggregate
+		expected string
+	}{
+		{Aggregate{Func: "COUNT"}, `SELECT COUNT(*) FROM orders WHERE status = $1`},
+		{Aggregate{Func: "count", Column: "user_id", Distinct: true}, `SELECT COUNT(DISTINCT user_id) FROM orders WHERE status = $1`},
+		{Aggregate{Func: "SUM", Column: "amount"}, `SELECT SUM(amount) FROM orders WHERE status = $1`},
+		{Aggregate{Func: "AVG", Column: "amount"}, `SELECT AVG(amount) FROM orders WHERE status = $1`},
+		{Aggregate{Func: "MIN", Column: "created_at"}, `SELECT MIN(created_at) FROM orders WHERE status = $1`},
+		{Aggregate{Func: "MAX", Column: "created_at"}, `SELECT MAX(created_at) FROM orders WHERE status = $1`},
+	}
+	for _, tt := range tests {
+		sqlQuery, args, err := AggregateQuery("orders", options, tt.agg)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, sqlQuery)
+		assert.Equal(t, []interface{}{"paid"}, args)
+	}
+
+	for _, agg := range []Aggregate{{Func: "MEDIAN", Column: "amount"}, {Func: "SUM"}, {Func: "COUNT", Distinct: true}} {
+		_, _, err := AggregateQuery("orders", options, agg)
+		assert.ErrorIs(t, err, ErrInvalidAggregate)
+	}
+
+	sqlQuery, _, err := AggregateQuery("orders", options.WithSelectPrefix("DISTINCT"), Aggregate{Func: "COUNT", Column: "user_id", Distinct: true})
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(DISTINCT user_id) FROM orders WHERE status = $1`, sqlQuery)
+
+	_, _, err = AggregateQuery("orders", options, Aggregate{Func: "SUM", Column: "amount) FROM orders; --"})
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+	_, _, err = AggregateQuery("orders", options.WithAllowedColumns("status", "amount"), Aggregate{Func: "MAX", Column: "created_at"})
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+}
+
+func TestChangedOnly(t *testing.T) {
+	tests := []struct {
+		flavor   Flavor
+		expected string
+	}{
+		{PostgreSQLFlavor, `UPDATE players SET age = $1, name = $2 WHERE id = $3 AND (age IS DISTINCT FROM $4 OR name IS DISTINCT FROM $5)`},
+		{MySQLFlavor, "UPDATE players SET age = ?, name = ? WHERE id = ? AND (NOT (age <=> ?) OR NOT (name <=> ?))"},
+		{SQLiteFlavor, "UPDATE players SET age = ?, name = ? WHERE id = ? AND (age IS NOT ? OR name IS NOT ?)"},
+	}
+	for _, tt := range tests {
+		options := NewUpdateOptions(tt.flavor).
+			WithAssignment("name", "Ronaldinho").
+			WithAssignment("age", nil).
+			WithFilter("id", 1).
+			WithChangedOnly()
+		sqlQuery, args := UpdateWithOptionsQuery("players", options)
+		assert.Equal(t, tt.expected, sqlQuery)
+		assert.Equal(t, []interface{}{nil, "Ronaldinho", 1, nil, "Ronaldinho"}, args)
+	}
+}
+
+func TestTimeInInterval(t *testing.T) {
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("room_id", 1).WithTimeInInterval(at, "start_at", "end_at")
+	sqlQuery, args := FindAllQuery("bookings", options)
+	assert.Equal(t, `SELECT * FROM bookings WHERE room_id = $1 AND ($2 >= start_at AND $2 < end_at)`, sqlQuery)
+	assert.Equal(t, []interface{}{1, at}, args)
+	sqlQuery, namedArgs := FindAllQueryNamed("bookings", options)
+	assert.Equal(t, `SELECT * FROM bookings WHERE room_id = :room_id AND (:arg_2 >= start_at AND :arg_2 < end_at)`, sqlQuery)
+	assert.Equal(t, map[string]interface{}{"room_id": 1, "arg_2": at}, namedArgs)
+
+	sqlQuery, args = FindQuery("bookings", NewFindOptions(MySQLFlavor).WithTimeInInterval(at, "start_at", "end_at"))
+	assert.Equal(t, "SELECT * FROM bookings WHERE (? >= start_at AND ? < end_at)", sqlQuery)
+	assert.Equal(t, []interface{}{at, at}, args)
+}
+
+func TestWithOrder(t *testing.T) {
+	t.Run("accumulate", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).
+			WithOrder("name", Asc).
+			WithOrder("players.created_at", Desc).
+			WithTieBreaker("id").
+			WithLimit(10)
+		sqlQuery, _, err := FindAllQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM players ORDER BY name ASC, players.created_at DESC, id LIMIT 10 OFFSET 0`, sqlQuery)
+		assert.Len(t, options.Orders, 2)
+	})
+
+	t.Run("invalid direction", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).WithOrder("name", Direction(5)).WithOrder("id", Desc)
+		_, _, err := FindAllQueryE("players", options)
+		assert.ErrorIs(t, err, ErrInvalidDirection)
+		sqlQuery, _ := FindAllQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players ORDER BY id DESC`, sqlQuery)
+	})
+
+	t.Run("invalid column", func(t *testing.T) {
+		for _, column := range []string{"", "name; DROP TABLE players", "(SELECT 1)", "players.", "1name"} {
+			_, _, err := FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithOrder(column, Asc))
+			assert.ErrorIs(t, err, ErrInvalidColumn)
+		}
+	})
+}
+
+func TestAllowedColumns(t *testing.T) {
+	t.Run("allowed", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).
+			WithAllowedColumns("id", "name").
+			WithFilter("id.gte", 10).
+			WithFilter("name", "Ronaldinho").
+			WithOrderBy("name desc").
+			WithOrder("id", Asc)
+		sqlQuery, args, err := FindAllQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM players WHERE id >= $1 AND name = $2 ORDER BY name desc, id ASC`, sqlQuery)
+		assert.Equal(t, []interface{}{10, "Ronaldinho"}, args)
+	})
+
+	t.Run("disallowed filter", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).
+			WithAllowedColumns("id", "name").
+			WithFilter("id", 1).
+			WithFilter("password.like", "a%").
+			WithJSONFilter("data", "role", "admin")
+		_, _, err := FindQueryE("players", options)
+		assert.ErrorIs(t, err, ErrInvalidColumn)
+		sqlQuery, args := FindQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE id = $1`, sqlQuery)
+		assert.Equal(t, []interface{}{1}, args)
+	})
+
+	t.Run("disallowed order", func(t *testing.T) {
+		for _, options := range []*FindAllOptions{
+			NewFindAllOptions(PostgreSQLFlavor).WithAllowedColumns("id").WithOrderBy("password"),
+			NewFindAllOptions(PostgreSQLFlavor).WithAllowedColumns("id").WithOrderBy("id, (SELECT 1)"),
+			NewFindAllOptions(PostgreSQLFlavor).WithAllowedColumns("id").WithOrder("password", Desc),
+		} {
+			_, _, err := FindAllQueryE("players", options)
+			assert.ErrorIs(t, err, ErrInvalidColumn)
+			sqlQuery, _ := FindAllQuery("players", options)
+			assert.Equal(t, `SELECT * FROM players`, sqlQuery)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, _, err := DeleteWithOptionsQueryE("players", NewDeleteOptions(MySQLFlavor).WithAllowedColumns("id").WithFilter("team_id", 1))
+		assert.ErrorIs(t, err, ErrInvalidColumn)
+	})
+}
+
+func TestCtidFilter(t *testing.T) {
+	t.Run("postgresql", func(t *testing.T) {
+		options := NewUpdateOptions(PostgreSQLFlavor).WithAssignment("status", "done").WithCtidFilter("(12,3)")
+		sqlQuery, args, err := UpdateWithOptionsQueryE("jobs", options)
+		assert.NoError(t, err)
+		assert.Equal(t, `UPDATE jobs SET status = $1 WHERE ctid = $2`, sqlQuery)
+		assert.Equal(t, []interface{}{"done", "(12,3)"}, args)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, ctid := range []string{"", "12,3", "(12)", "(12,-3)", "(a,b)", "(1,2,3)"} {
+			_, _, err := FindQueryE("jobs", NewFindOptions(PostgreSQLFlavor).WithCtidFilter(ctid))
+			assert.ErrorIs(t, err, ErrInvalidValue)
+		}
+	})
+
+	t.Run("unsupported flavors", func(t *testing.T) {
+		_, _, err := FindQueryE("jobs", NewFindOptions(MySQLFlavor).WithCtidFilter("(0,1)"))
+		assert.ErrorIs(t, err, ErrUnsupportedOperator)
+	})
+}
+
+func TestDateIn(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).WithDateIn("created_on", "2024-01-01, 2024-01-02", "")
+		sqlQuery, args, err := FindAllQueryE("orders", options)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT * FROM orders WHERE created_on IN ($1, $2)`, sqlQuery)
+		assert.Equal(t, []interface{}{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, args)
+	})
+
+	t.Run("layout", func(t *testing.T) {
+		options := NewFindOptions(MySQLFlavor).WithDateIn("created_on", "01/02/2024", "02/01/2006")
+		_, args, err := FindQueryE("orders", options)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)}, args)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		options := NewFindOptions(PostgreSQLFlavor).WithDateIn("created_on", "2024-01-01,2024-13-01", "")
+		_, _, err := FindQueryE("orders", options)
+		assert.ErrorIs(t, err, ErrInvalidValue)
+	})
+}
+
+func TestTableAlias(t *testing.T) {
+	t.Run("join", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).
+			WithTableAlias("p").
+			WithFields([]string{"id", "name", "t.name AS team_name", "COUNT(*) AS total"}).
+			WithJoin("teams t", "t.id = p.team_id").
+			WithFilter("id.in", "1,2").
+			WithFilter("t.active", true).
+			WithFilter("t.country.in", "BR,AR").
+			WithSoftDelete("deleted_at").
+			WithOrder("name", Asc).
+			WithTieBreaker("id").
+			WithLimit(10)
+		sqlQuery, args, err := FindAllQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, `SELECT p.id, p.name, t.name AS team_name, COUNT(*) AS total FROM players AS p JOIN teams t ON t.id = p.team_id WHERE p.id IN ($1, $2) AND t.active = $3 AND t.country IN ($4, $5) AND p.deleted_at IS NULL ORDER BY p.name ASC, p.id LIMIT 10 OFFSET 0`, sqlQuery)
+		assert.Equal(t, []interface{}{"1", "2", true, "BR", "AR"}, args)
+	})
+
+	t.Run("find", func(t *testing.T) {
+		options := NewFindOptions(MySQLFlavor).WithTableAlias("p").WithFilter("id", 1)
+		sqlQuery, _ := FindQuery("players", options)
+		assert.Equal(t, "SELECT * FROM players AS p WHERE p.id = ?", sqlQuery)
+	})
+}
+
+func TestDistinctFrom(t *testing.T) {
+	tests := []struct {
+		flavor   Flavor
+		expected string
+	}{
+		{PostgreSQLFlavor, `SELECT * FROM players WHERE nickname IS DISTINCT FROM $1`},
+		{SQLiteFlavor, "SELECT * FROM players WHERE nickname IS NOT ?"},
+		{MySQLFlavor, "SELECT * FROM players WHERE NOT (nickname <=> ?)"},
+		{MariaDBFlavor, "SELECT * FROM players WHERE NOT (nickname <=> ?)"},
+	}
+	for _, tt := range tests {
+		sqlQuery, args := FindQuery("players", NewFindOptions(tt.flavor).WithFilter("nickname.distinctfrom", "R10"))
+		assert.Equal(t, tt.expected, sqlQuery)
+		assert.Equal(t, []interface{}{"R10"}, args)
+	}
+}
+
+func TestRoundedFilter(t *testing.T) {
+	tests := []struct {
+		flavor   Flavor
+		op       string
+		expected string
+	}{
+		{PostgreSQLFlavor, "", `SELECT * FROM payments WHERE ROUND(amount, 2) = $1`},
+		{MySQLFlavor, "gte", "SELECT * FROM payments WHERE ROUND(amount, 2) >= ?"},
+		{SQLiteFlavor, "not", "SELECT * FROM payments WHERE ROUND(amount, 2) <> ?"},
+	}
+	for _, tt := range tests {
+		sqlQuery, args := FindQuery("payments", NewFindOptions(tt.flavor).WithRoundedFilter("amount", 2, tt.op, 10.5))
+		assert.Equal(t, tt.expected, sqlQuery)
+		assert.Equal(t, []interface{}{10.5}, args)
+	}
+
+	_, _, err := FindQueryE("payments", NewFindOptions(PostgreSQLFlavor).WithRoundedFilter("amount", 2, "like", 10.5))
+	assert.ErrorIs(t, err, ErrUnknownOperator)
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("two columns", func(t *testing.T) {
+		options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1).WithSearch("ronaldo_", "name", "nickname")
+		sqlQuery, args := FindAllQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 AND (name LIKE $2 OR nickname LIKE $3)`, sqlQuery)
+		assert.Equal(t, []interface{}{1, `%ronaldo\_%`, `%ronaldo\_%`}, args)
+	})
+
+	t.Run("three columns", func(t *testing.T) {
+		options := NewFindAllOptions(SQLiteFlavor).WithSearch("ronaldo", "name", "nickname", "email")
+		sqlQuery, args := FindAllQuery("players", options)
+		assert.Equal(t, `SELECT * FROM players WHERE (name LIKE ? ESCAPE '\' OR nickname LIKE ? ESCAPE '\' OR email LIKE ? ESCAPE '\')`, sqlQuery)
+		assert.Equal(t, []interface{}{"%ronaldo%", "%ronaldo%", "%ronaldo%"}, args)
+	})
+
+	t.Run("empty value", func(t *testing.T) {
+		sqlQuery, _ := FindAllQuery("players", NewFindAllOptions(MySQLFlavor).WithSearch("", "name"))
+		assert.Equal(t, "SELECT * FROM players", sqlQuery)
+	})
+}
+
+func TestColumnComparison(t *testing.T) {
+	tests := []struct {
+		op       string
+		expected string
+	}{
+		{"<", `SELECT * FROM events WHERE status = $1 AND start_date < end_date`},
+		{">", `SELECT * FROM events WHERE status = $1 AND start_date > end_date`},
+		{"=", `SELECT * FROM events WHERE status = $1 AND start_date = end_date`},
+	}
+	for _, tt := range tests {
+		options := NewFindOptions(PostgreSQLFlavor).WithFilter("status", "open").WithColumnComparison("start_date", tt.op, "end_date")
+		sqlQuery, args := FindQuery("events", options)
+		assert.Equal(t, tt.expected, sqlQuery)
+		assert.Equal(t, []interface{}{"open"}, args)
+	}
+
+	_, _, err := FindQueryE("events", NewFindOptions(PostgreSQLFlavor).WithColumnComparison("start_date", "LIKE", "end_date"))
+	assert.ErrorIs(t, err, ErrUnknownOperator)
+	_, _, err = FindQueryE("events", NewFindOptions(PostgreSQLFlavor).WithColumnComparison("start_date", "<", "'2024-01-01'"))
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+}
+
+func TestJSONAggQuery(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"id", "name"}).
+		WithFilter("team_id", 1).
+		WithFilter("name.like", "R%").
+		WithOrderBy("id").
+		WithLimit(10)
+	sqlQuery, args, err := JSONAggQuery("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT json_agg(t) FROM (SELECT id, name FROM players WHERE name LIKE $1 AND team_id = $2 ORDER BY id LIMIT 10 OFFSET 0) AS t`, sqlQuery)
+	assert.Equal(t, []interface{}{"R%", 1}, args)
+
+	_, _, err = JSONAggQuery("players", NewFindAllOptions(MySQLFlavor))
+	assert.ErrorIs(t, err, ErrUnsupportedFlavor)
+}
+
+func TestInsertIgnoreQuery(t *testing.T) {
+	r10 := player{ID: 1, Name: "Ronaldinho 10"}
+	expectedArgs := []interface{}{1, "Ronaldinho 10"}
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `INSERT INTO players (id, name) VALUES ($1, $2) ON CONFLICT DO NOTHING`},
+		{SQLiteFlavor, `INSERT INTO players (id, name) VALUES (?, ?) ON CONFLICT DO NOTHING`},
+		{MySQLFlavor, "INSERT IGNORE INTO players (id, name) VALUES (?, ?)"},
+	}
+	for _, tt := range tests {
+		sqlQuery, args := InsertIgnoreQuery(tt.flavor, "insert", "players", &r10)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, expectedArgs, args)
+	}
+}
+
+func TestNonASCIIFilter(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1).WithNonASCIIFilter("name")
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 AND name !~ '^[[:ascii:]]*$'`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+
+	options = NewFindAllOptions(MySQLFlavor).WithNonASCIIFilter("name")
+	sqlQuery, _, err = FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM players WHERE name <> CONVERT(name USING ascii)", sqlQuery)
+
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(SQLiteFlavor).WithNonASCIIFilter("name"))
+	assert.ErrorIs(t, err, ErrUnsupportedFlavor)
+}
+
+func TestSelectPrefix(t *testing.T) {
+	options := NewFindAllOptions(MySQLFlavor).
+		WithFields([]string{"id", "name"}).
+		WithSelectPrefix("SQL_CALC_FOUND_ROWS", "high_priority").
+		WithFilter("team_id", 1).
+		WithLimit(10)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT SQL_CALC_FOUND_ROWS HIGH_PRIORITY id, name FROM players WHERE team_id = ? LIMIT 10 OFFSET 0", sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithFields([]string{"team_id"}).WithSelectPrefix("DISTINCT").WithTableAlias("p")
+	sqlQuery, _, err = FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT DISTINCT p.team_id FROM players AS p", sqlQuery)
+
+	options = NewFindAllOptions(MySQLFlavor).WithSelectPrefix("DISTINCT", "1; DROP TABLE players; --")
+	sqlQuery, _ = FindAllQuery("players", options)
+	assert.Equal(t, "SELECT DISTINCT * FROM players", sqlQuery)
+	_, _, err = FindAllQueryE("players", options)
+	assert.ErrorIs(t, err, ErrInvalidValue)
+}
+
+func TestPopcountFilter(t *testing.T) {
+	options := NewFindAllOptions(MySQLFlavor).WithFilter("flags.popcount.gte", 3).WithFilter("team_id", 1)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM players WHERE BIT_COUNT(flags) >= ? AND team_id = ?", sqlQuery)
+	assert.Equal(t, []interface{}{3, 1}, args)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithFilter("flags.popcount", 2).WithTableAlias("p")
+	sqlQuery, args, err = FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM players AS p WHERE bit_count(p.flags) = $1", sqlQuery)
+	assert.Equal(t, []interface{}{2}, args)
+
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(SQLiteFlavor).WithFilter("flags.popcount.gte", 3))
+	assert.ErrorIs(t, err, ErrUnsupportedOperator)
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(MySQLFlavor).WithFilter("flags.popcount.between", 3))
+	assert.ErrorIs(t, err, ErrUnknownOperator)
+}
+
+func BenchmarkFindAllQuery(b *testing.B) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"id", "name", "email"}).
+		WithFilter("team_id", 1).
+		WithFilter("id.in", "1,2,3,4,5,6,7,8").
+		WithFilter("name.like", "R%").
+		WithFilter("deleted_at.null", true).
+		WithOrderBy("id DESC").
+		WithLimit(20).
+		WithOffset(40)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FindAllQuery("players", options)
+	}
+}
+
+func BenchmarkFindQuery(b *testing.B) {
+	options := NewFindOptions(PostgreSQLFlavor).WithFilter("id", 1).WithFilter("team_id.in", "1,2,3")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FindQuery("players", options)
+	}
+}
+
+// benchmarkOptions keeps the benchmarked options alive, so the copies of the With* methods escape to the heap
+// like they do when the options are returned to the caller.
+var benchmarkOptions *FindAllOptions
+
+func BenchmarkFindAllOptionsWith(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkOptions = NewFindAllOptions(PostgreSQLFlavor).
+			WithFields([]string{"id", "name"}).
+			WithFilter("team_id", 1).
+			WithFilter("id.in", "1,2,3").
+			WithOrderBy("id").
+			WithLimit(20)
+	}
+}
+
+func BenchmarkNewFindAllOptions(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		benchmarkOptions = NewFindAllOptions(
+			PostgreSQLFlavor,
+			WithFields("id", "name"),
+			WithFilter("team_id", 1),
+			WithFilter("id.in", "1,2,3"),
+			WithOrderBy("id"),
+			WithLimit(20),
+		)
+	}
+}
+
+func BenchmarkUpdateWithOptionsQuery(b *testing.B) {
+	options := NewUpdateOptions(PostgreSQLFlavor).
+		WithAssignment("name", "Ronaldinho").
+		WithAssignment("updated_at", "2021-01-01").
+		WithFilter("id.in", "1,2,3")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		UpdateWithOptionsQuery("players", options)
+	}
+}
+
+func TestFindAllWithCountQuery(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"id", "name"}).
+		WithFilter("team_id", 1).
+		WithFilter("id.in", "1,2,3").
+		WithOrderBy("id DESC").
+		WithLimit(10).
+		WithOffset(20).
+		WithForUpdate("")
+	pageSQL, pageArgs, countSQL, countArgs := FindAllWithCountQuery("players", options)
+	assert.Equal(t, `SELECT id, name FROM players WHERE id IN ($1, $2, $3) AND team_id = $4 ORDER BY id DESC LIMIT 10 OFFSET 20 FOR UPDATE`, pageSQL)
+	assert.Equal(t, `SELECT COUNT(*) FROM players WHERE id IN ($1, $2, $3) AND team_id = $4`, countSQL)
+	assert.Equal(t, []interface{}{"1", "2", "3", 1}, pageArgs)
+	assert.Equal(t, pageArgs, countArgs)
+	pageWhere := strings.SplitN(strings.SplitN(pageSQL, " WHERE ", 2)[1], " ORDER BY ", 2)[0]
+	countWhere := strings.SplitN(countSQL, " WHERE ", 2)[1]
+	assert.Equal(t, pageWhere, countWhere)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"team_id"}).
+		WithSelectPrefix("DISTINCT").
+		WithFilter("active", true).
+		WithOrderBy("team_id").
+		WithLimit(10)
+	pageSQL, pageArgs, countSQL, countArgs = FindAllWithCountQuery("players", options)
+	assert.Equal(t, `SELECT DISTINCT team_id FROM players WHERE active = $1 ORDER BY team_id LIMIT 10 OFFSET 0`, pageSQL)
+	assert.Equal(t, `SELECT COUNT(*) FROM (SELECT DISTINCT team_id FROM players WHERE active = $1) AS t`, countSQL)
+	assert.Equal(t, []interface{}{true}, pageArgs)
+	assert.Equal(t, pageArgs, countArgs)
+}
+
+func TestSnapshotStatement(t *testing.T) {
+	assert.Equal(t, "SET TRANSACTION SNAPSHOT '00000003-0000001B-1'", SnapshotStatement("00000003-0000001B-1"))
+	assert.Equal(t, "SET TRANSACTION SNAPSHOT '000003A1-1'", SnapshotStatement("000003A1-1"))
+	assert.Equal(t, "", SnapshotStatement(""))
+	assert.Equal(t, "", SnapshotStatement("00000003"))
+	assert.Equal(t, "", SnapshotStatement("00000003-1'; DROP TABLE players; --"))
+}
+
+func TestDateEqualsFilter(t *testing.T) {
+	day := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `SELECT * FROM players WHERE team_id = $1 AND created_at::date = $2`},
+		{MySQLFlavor, "SELECT * FROM players WHERE team_id = ? AND DATE(created_at) = ?"},
+		{SQLiteFlavor, "SELECT * FROM players WHERE team_id = ? AND DATE(created_at) = ?"},
+	}
+	for _, tt := range tests {
+		options := NewFindAllOptions(tt.flavor).WithFilter("team_id", 1).WithDateEqualsFilter("created_at", day)
+		sqlQuery, args, err := FindAllQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{1, "2024-03-15"}, args)
+	}
+}
+
+func TestNotILikeFilter(t *testing.T) {
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `SELECT * FROM players WHERE name NOT ILIKE $1`},
+		{MySQLFlavor, "SELECT * FROM players WHERE LOWER(name) NOT LIKE LOWER(?)"},
+		{SQLiteFlavor, "SELECT * FROM players WHERE LOWER(name) NOT LIKE LOWER(?)"},
+	}
+	for _, tt := range tests {
+		options := NewFindAllOptions(tt.flavor).WithFilter("name.notilike", "ron%")
+		sqlQuery, args, err := FindAllQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{"ron%"}, args)
+	}
+
+	options := NewUpdateOptions(PostgreSQLFlavor).WithAssignment("active", false).WithFilter("name.notilike", "ron%")
+	sqlQuery, args := UpdateWithOptionsQuery("players", options)
+	assert.Equal(t, `UPDATE players SET active = $1 WHERE name NOT ILIKE $2`, sqlQuery)
+	assert.Equal(t, []interface{}{false, "ron%"}, args)
+}
+
+func TestSeededRandomOrder(t *testing.T) {
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `SELECT * FROM players WHERE team_id = $1 ORDER BY md5(id::text || $2), id LIMIT 10 OFFSET 0`},
+		{MySQLFlavor, "SELECT * FROM players WHERE team_id = ? ORDER BY MD5(CONCAT(id, ?)), id LIMIT 10 OFFSET 0"},
+	}
+	for _, tt := range tests {
+		options := NewFindAllOptions(tt.flavor).
+			WithFilter("team_id", 1).
+			WithSeededRandomOrder("id", "2024-03-15").
+			WithTieBreaker("id").
+			WithLimit(10)
+		sqlQuery, args, err := FindAllQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{1, "2024-03-15"}, args)
+	}
+
+	_, _, err := FindAllQueryE("players", NewFindAllOptions(SQLiteFlavor).WithSeededRandomOrder("id", "seed"))
+	assert.ErrorIs(t, err, ErrUnsupportedFlavor)
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithSeededRandomOrder("id; DROP TABLE players", "seed"))
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+}
+
+func TestInFilterSlices(t *testing.T) {
+	first := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFilter("created_at.in", []time.Time{first, second}).
+		WithFilter("team_id.notin", []int{1, 2, 3})
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE created_at IN ($1, $2) AND team_id NOT IN ($3, $4, $5)`, sqlQuery)
+	assert.Equal(t, []interface{}{first, second, 1, 2, 3}, args)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithFilter("id.in", []int64{}).WithEmptyIn(EmptyInMatchNothing)
+	sqlQuery, args = FindAllQuery("players", options)
+	assert.Equal(t, `SELECT * FROM players WHERE 1 = 0`, sqlQuery)
+	assert.Empty(t, args)
+}
+
+func TestArrayIndexFilter(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1).WithArrayIndexFilter("tags", 1, "", "striker")
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 AND tags[1] = $2`, sqlQuery)
+	assert.Equal(t, []interface{}{1, "striker"}, args)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithArrayIndexFilter("scores", 2, "gte", 10)
+	sqlQuery, args, err = FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE scores[2] >= $1`, sqlQuery)
+	assert.Equal(t, []interface{}{10}, args)
+
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithArrayIndexFilter("tags", 0, "", "striker"))
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(MySQLFlavor).WithArrayIndexFilter("tags", 1, "", "striker"))
+	assert.ErrorIs(t, err, ErrUnsupportedFlavor)
+}
+
+func TestMaxInSize(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("id.in", "1,2,3").WithMaxInSize(3)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE id IN ($1, $2, $3)`, sqlQuery)
+	assert.Equal(t, []interface{}{"1", "2", "3"}, args)
+
+	options = options.WithFilter("id.in", "1,2,3,4").WithFilter("team_id", 1)
+	_, _, err = FindAllQueryE("players", options)
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	sqlQuery, args = FindAllQuery("players", options)
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+
+	deleteOptions := NewDeleteOptions(PostgreSQLFlavor).WithFilter("id.notin", []int{1, 2, 3}).WithMaxInSize(2)
+	_, _, err = DeleteWithOptionsQueryE("players", deleteOptions)
+	assert.ErrorIs(t, err, ErrInvalidValue)
+}
+
+func TestFetchQuery(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1).WithOrderBy("id").WithLimit(10).WithOffset(20)
+	sqlQuery, args := FindAllQuery("players", options)
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 ORDER BY id LIMIT 10 OFFSET 20`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sqlQuery, args = FindAllQuery("players", options.WithFetch())
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sqlQuery, _ = FindAllQuery("players", options.WithFetch().WithForUpdate(""))
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY FOR UPDATE`, sqlQuery)
+
+	sqlQuery, _ = FindAllQuery("players", NewFindAllOptions(PostgreSQLFlavor).WithFetch())
+	assert.Equal(t, `SELECT * FROM players`, sqlQuery)
+
+	sqlQuery, _ = FindAllQuery("players", NewFindAllOptions(MySQLFlavor).WithLimit(10).WithFetch())
+	assert.Equal(t, "SELECT * FROM players LIMIT 10 OFFSET 0", sqlQuery)
+}
+
+func TestSimilarToFilter(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("code.similarto", "(BR|AR)-[0-9]+").WithFilter("team_id", 1)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE code SIMILAR TO $1 AND team_id = $2`, sqlQuery)
+	assert.Equal(t, []interface{}{"(BR|AR)-[0-9]+", 1}, args)
+
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(MySQLFlavor).WithFilter("code.similarto", "BR-%"))
+	assert.ErrorIs(t, err, ErrUnsupportedOperator)
+}
+
+func TestExistsFilter(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFilter("team_id", 1).
+		WithExists("goals", "goals.player_id = players.id", map[string]interface{}{"minute.gt": 80, "season": 2024}).
+		WithFilter("active", true)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE active = $1 AND team_id = $2 AND EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND minute > $3 AND season = $4)`, sqlQuery)
+	assert.Equal(t, []interface{}{true, 1, 80, 2024}, args)
+
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithExists("goals", "goals.player_id = players.id", map[string]interface{}{"minute.between": 1}))
+	assert.ErrorIs(t, err, ErrUnknownOperator)
+}
+
+func TestCursorToken(t *testing.T) {
+	token := EncodeCursor(map[string]interface{}{"id": 42, "score": 9.5, "name": "Ronaldinho"})
+	values, err := DecodeCursor(token)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": int64(42), "score": 9.5, "name": "Ronaldinho"}, values)
+
+	_, err = DecodeCursor("not a token")
+	assert.ErrorIs(t, err, ErrInvalidValue)
+
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFilter("team_id", 1).
+		WithOrder("score", Desc).
+		WithTieBreaker("id").
+		WithCursorToken(token).
+		WithLimit(10)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 AND (score < $2 OR (score = $3 AND id > $4)) ORDER BY score DESC, id LIMIT 10 OFFSET 0`, sqlQuery)
+	assert.Equal(t, []interface{}{1, 9.5, 9.5, int64(42)}, args)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithTieBreaker("id").WithCursorToken(EncodeCursor(map[string]interface{}{"id": 42}))
+	sqlQuery, args, err = FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE (id > $1) ORDER BY id`, sqlQuery)
+	assert.Equal(t, []interface{}{int64(42)}, args)
+
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithOrder("created_at", Asc).WithCursorToken(token))
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithCursorToken(token))
+	assert.ErrorIs(t, err, ErrMissingOrderBy)
+}
+
+func TestNotExistsFilter(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFilter("team_id", 1).
+		WithNotExists("goals", "goals.player_id = players.id", map[string]interface{}{"season": 2024}).
+		WithExists("assists", "assists.player_id = players.id", map[string]interface{}{"season": 2023})
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 AND NOT EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id AND season = $2) AND EXISTS (SELECT 1 FROM assists WHERE assists.player_id = players.id AND season = $3)`, sqlQuery)
+	assert.Equal(t, []interface{}{1, 2024, 2023}, args)
+
+	deleteOptions := NewDeleteOptions(MySQLFlavor).WithNotExists("goals", "goals.player_id = players.id", nil).WithFilter("active", false)
+	sqlQuery, args = DeleteWithOptionsQuery("players", deleteOptions)
+	assert.Equal(t, "DELETE FROM players WHERE active = ? AND NOT EXISTS (SELECT 1 FROM goals WHERE goals.player_id = players.id)", sqlQuery)
+	assert.Equal(t, []interface{}{false}, args)
+}
+
+func TestNullSafeEqual(t *testing.T) {
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{MySQLFlavor, "SELECT * FROM players WHERE nickname <=> ? AND team_id = ?"},
+		{MariaDBFlavor, "SELECT * FROM players WHERE nickname <=> ? AND team_id = ?"},
+		{PostgreSQLFlavor, `SELECT * FROM players WHERE nickname IS NOT DISTINCT FROM $1 AND team_id = $2`},
+		{SQLiteFlavor, "SELECT * FROM players WHERE nickname IS ? AND team_id = ?"},
+	}
+	for _, tt := range tests {
+		options := NewFindAllOptions(tt.flavor).WithNullSafeEqual("nickname", nil).WithFilter("team_id", 1)
+		sqlQuery, args, err := FindAllQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{nil, 1}, args)
+	}
+}
+
+func TestSkipNil(t *testing.T) {
+	var nickname *string
+	filters := map[string]interface{}{"team_id": 1, "deleted_at": nil, "nickname": nickname, "age.gte": nil}
+
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilters(filters).WithSkipNil()
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1).WithFilter("deleted_at", nil)
+	sqlQuery, args, err = FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE deleted_at IS NULL AND team_id = $1`, sqlQuery)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestDeepPageQuery(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"id", "name"}).
+		WithFilter("team_id", 1).
+		WithOrderBy("name").
+		WithLimit(10).
+		WithOffset(100000)
+	sqlQuery, args, err := DeepPageQuery("players", "id", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT id, name FROM players WHERE team_id = $1 AND id >= (SELECT id FROM players WHERE team_id = $2 ORDER BY id LIMIT 1 OFFSET $3) ORDER BY id LIMIT 10 OFFSET 0`, sqlQuery)
+	assert.Equal(t, []interface{}{1, 1, 100000}, args)
+
+	sqlQuery, args, err = DeepPageQuery("players", "id", NewFindAllOptions(MySQLFlavor).WithLimit(10).WithOffset(50))
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM players WHERE id >= (SELECT id FROM players ORDER BY id LIMIT 1 OFFSET ?) ORDER BY id LIMIT 10 OFFSET 0", sqlQuery)
+	assert.Equal(t, []interface{}{50}, args)
+
+	_, _, err = DeepPageQuery("players", "id; DROP TABLE players", options)
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+}
+
+func TestGreatestAndLeastFilters(t *testing.T) {
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `SELECT * FROM matches WHERE GREATEST(home_goals, away_goals) > $1 AND LEAST(home_goals, away_goals) >= $2`},
+		{MySQLFlavor, "SELECT * FROM matches WHERE GREATEST(home_goals, away_goals) > ? AND LEAST(home_goals, away_goals) >= ?"},
+		{SQLiteFlavor, "SELECT * FROM matches WHERE MAX(home_goals, away_goals) > ? AND MIN(home_goals, away_goals) >= ?"},
+	}
+	columns := []string{"home_goals", "away_goals"}
+	for _, tt := range tests {
+		options := NewFindAllOptions(tt.flavor).WithGreatestFilter(columns, "gt", 3).WithLeastFilter(columns, "gte", 1)
+		sqlQuery, args, err := FindAllQueryE("matches", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{3, 1}, args)
+	}
+
+	_, _, err := FindAllQueryE("matches", NewFindAllOptions(PostgreSQLFlavor).WithGreatestFilter([]string{"home_goals", "1); DROP TABLE matches; --"}, "gt", 3))
+	assert.ErrorIs(t, err, ErrInvalidColumn)
+	_, _, err = FindAllQueryE("matches", NewFindAllOptions(PostgreSQLFlavor).WithLeastFilter(columns, "between", 3))
+	assert.ErrorIs(t, err, ErrUnknownOperator)
+}
+
+func TestFindAllQueryInterpolated(t *testing.T) {
+	createdAt := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `SELECT * FROM players WHERE created_at >= '2024-03-15 10:00:00 UTC' AND id IN (1, 2) AND name = E'R\'10' LIMIT 10 OFFSET 0`},
+		{MySQLFlavor, `SELECT * FROM players WHERE created_at >= '2024-03-15 10:00:00' AND id IN (1, 2) AND name = 'R\'10' LIMIT 10 OFFSET 0`},
+		{SQLiteFlavor, `SELECT * FROM players WHERE created_at >= '2024-03-15 10:00:00.000' AND id IN (1, 2) AND name = 'R\'10' LIMIT 10 OFFSET 0`},
+	}
+	for _, tt := range tests {
+		options := NewFindAllOptions(tt.flavor).
+			WithFilter("name", "R'10").
+			WithFilter("id.in", []int{1, 2}).
+			WithFilter("created_at.gte", createdAt).
+			WithLimit(10)
+		sqlQuery, err := FindAllQueryInterpolated("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+	}
+
+	_, err := FindAllQueryInterpolated("players", NewFindAllOptions(PostgreSQLFlavor).WithFilter("id.between", 1))
+	assert.ErrorIs(t, err, ErrUnknownOperator)
+}
+
+func TestIEqFilter(t *testing.T) {
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `SELECT * FROM users WHERE LOWER(email) = LOWER($1)`},
+		{MySQLFlavor, "SELECT * FROM users WHERE LOWER(email) = LOWER(?)"},
+		{SQLiteFlavor, "SELECT * FROM users WHERE LOWER(email) = LOWER(?)"},
+	}
+	for _, tt := range tests {
+		options := NewFindOptions(tt.flavor).WithFilter("email.ieq", "Foo_1%@Bar.com")
+		sqlQuery, args, err := FindQueryE("users", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{"Foo_1%@Bar.com"}, args)
+	}
+
+	options := NewDeleteOptions(PostgreSQLFlavor).WithFilter("email.ieq", "Foo@Bar.com")
+	sqlQuery, args := DeleteWithOptionsQuery("users", options)
+	assert.Equal(t, `DELETE FROM users WHERE LOWER(email) = LOWER($1)`, sqlQuery)
+	assert.Equal(t, []interface{}{"Foo@Bar.com"}, args)
+}
+
+func TestValuesJoin(t *testing.T) {
+	rows := [][]interface{}{{1, 0.5}, {2, 0.25}}
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFields([]string{"players.*", "v.weight"}).
+		WithValuesJoin("v", []string{"id::bigint", "weight"}, rows).
+		WithFilter("active", true).
+		WithFilter("team_id", 10)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT players.*, v.weight FROM players JOIN (VALUES ($1::bigint, $2), ($3, $4)) AS v (id, weight) ON players.id = v.id WHERE active = $5 AND team_id = $6`, sqlQuery)
+	assert.Equal(t, []interface{}{1, 0.5, 2, 0.25, true, 10}, args)
+
+	options = NewFindAllOptions(MySQLFlavor).WithTableAlias("p").WithValuesJoin("v", []string{"id", "weight"}, rows)
+	sqlQuery, args, err = FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM players AS p JOIN (VALUES ROW(?, ?), ROW(?, ?)) AS v (id, weight) ON p.id = v.id", sqlQuery)
+	assert.Equal(t, []interface{}{1, 0.5, 2, 0.25}, args)
+
+	findOptions := NewFindOptions(SQLiteFlavor).WithValuesJoin("v", []string{"id::bigint", "weight"}, rows)
+	sqlQuery, args, err = FindQueryE("players", findOptions)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM players JOIN (SELECT ? AS id, ? AS weight UNION ALL SELECT ?, ?) AS v ON players.id = v.id", sqlQuery)
+	assert.Equal(t, []interface{}{1, 0.5, 2, 0.25}, args)
+
+	_, _, err = FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithValuesJoin("v", []string{"id", "weight"}, [][]interface{}{{1}}))
+	assert.ErrorIs(t, err, ErrInvalidValue)
+	for _, column := range []string{"id::int; DROP TABLE players", "id::int), (SELECT 1"} {
+		_, _, err = FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithValuesJoin("v", []string{column}, [][]interface{}{{1}}))
+		assert.ErrorIs(t, err, ErrInvalidColumn)
+	}
+	for _, column := range []string{"id::double precision", "id::numeric(10,2)", "id::text[]"} {
+		_, _, err = FindAllQueryE("players", NewFindAllOptions(PostgreSQLFlavor).WithValuesJoin("v", []string{column}, [][]interface{}{{1}}))
+		assert.NoError(t, err)
+	}
+}
+
+func TestRangeListFilter(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithFilter("active", true).
+		WithRangeListFilter("age", [][2]interface{}{{0, 12}, {65, 120}})
+	sqlQuery, args, err := FindAllQueryE("people", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM people WHERE active = $1 AND (age BETWEEN $2 AND $3 OR age BETWEEN $4 AND $5)`, sqlQuery)
+	assert.Equal(t, []interface{}{true, 0, 12, 65, 120}, args)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithRangeListFilter("age", nil).WithEmptyIn(EmptyInMatchNothing)
+	sqlQuery, _ = FindAllQuery("people", options)
+	assert.Equal(t, `SELECT * FROM people WHERE 1 = 0`, sqlQuery)
+}
+
+func TestDeleteJoin(t *testing.T) {
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `DELETE FROM players USING teams WHERE players.team_id = teams.id AND players.active = $1 AND teams.disbanded = $2`},
+		{MySQLFlavor, "DELETE players FROM players JOIN teams ON players.team_id = teams.id WHERE players.active = ? AND teams.disbanded = ?"},
+		{SQLiteFlavor, "DELETE FROM players WHERE rowid IN (SELECT players.rowid FROM players JOIN teams ON players.team_id = teams.id WHERE players.active = ? AND teams.disbanded = ?)"},
+	}
+	for _, tt := range tests {
+		options := NewDeleteOptions(tt.flavor).
+			WithJoin("teams", "players.team_id = teams.id").
+			WithFilter("teams.disbanded", true).
+			WithFilter("active", false)
+		sqlQuery, args, err := DeleteWithOptionsQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{false, true}, args)
+	}
+}
+
+func TestUpdateJoin(t *testing.T) {
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `UPDATE players SET rank = $1 FROM rankings WHERE players.id = rankings.player_id AND rankings.season = $2`},
+		{MySQLFlavor, "UPDATE players JOIN rankings ON players.id = rankings.player_id SET players.rank = ? WHERE rankings.season = ?"},
+	}
+	for _, tt := range tests {
+		options := NewUpdateOptions(tt.flavor).
+			WithAssignment("rank", 1).
+			WithJoin("rankings", "players.id = rankings.player_id").
+			WithFilter("rankings.season", 2024)
+		sqlQuery, args, err := UpdateWithOptionsQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{1, 2024}, args)
+	}
+}
+
+func TestUpdateJoinSharedColumn(t *testing.T) {
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `UPDATE players SET updated_at = $1 FROM rankings WHERE players.id = rankings.player_id AND rankings.season = $2 AND (players.updated_at IS DISTINCT FROM $3)`},
+		{MySQLFlavor, "UPDATE players JOIN rankings ON players.id = rankings.player_id SET players.updated_at = ? WHERE rankings.season = ? AND (NOT (players.updated_at <=> ?))"},
+		{MariaDBFlavor, "UPDATE players JOIN rankings ON players.id = rankings.player_id SET players.updated_at = ? WHERE rankings.season = ? AND (NOT (players.updated_at <=> ?))"},
+	}
+	for _, tt := range tests {
+		options := NewUpdateOptions(tt.flavor).
+			WithAssignment("updated_at", "2024-01-01").
+			WithJoin("rankings", "players.id = rankings.player_id").
+			WithFilter("rankings.season", 2024).
+			WithChangedOnly()
+		sqlQuery, args, err := UpdateWithOptionsQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{"2024-01-01", 2024, "2024-01-01"}, args)
+	}
+}
+
+func TestTextLikeFilter(t *testing.T) {
+	tests := []struct {
+		flavor           Flavor
+		expectedSQLQuery string
+	}{
+		{PostgreSQLFlavor, `SELECT * FROM players WHERE team_id = $1 AND id::text LIKE $2`},
+		{MySQLFlavor, "SELECT * FROM players WHERE team_id = ? AND CAST(id AS CHAR) LIKE ?"},
+		{SQLiteFlavor, `SELECT * FROM players WHERE team_id = ? AND CAST(id AS TEXT) LIKE ? ESCAPE '\'`},
+	}
+	for _, tt := range tests {
+		options := NewFindAllOptions(tt.flavor).WithFilter("team_id", 1).WithTextLikeFilter("id", "4_2")
+		sqlQuery, args, err := FindAllQueryE("players", options)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expectedSQLQuery, sqlQuery)
+		assert.Equal(t, []interface{}{1, `%4\_2%`}, args)
+	}
+
+	sqlQuery, _ := FindAllQuery("players", NewFindAllOptions(PostgreSQLFlavor).WithTextLikeFilter("id", ""))
+	assert.Equal(t, "SELECT * FROM players", sqlQuery)
+}
+
+func TestDMLLimit(t *testing.T) {
+	sqlQuery, args, err := DeleteWithOptionsQueryE("events", NewDeleteOptions(MySQLFlavor).WithFilter("processed", true).WithLimit(1000))
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM events WHERE processed = ? LIMIT 1000", sqlQuery)
+	assert.Equal(t, []interface{}{true}, args)
+
+	sqlQuery, args, err = UpdateWithOptionsQueryE("events", NewUpdateOptions(MySQLFlavor).WithAssignment("processed", true).WithFilter("processed", false).WithLimit(1000))
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE events SET processed = ? WHERE processed = ? LIMIT 1000", sqlQuery)
+	assert.Equal(t, []interface{}{true, false}, args)
+
+	for _, flavor := range []Flavor{PostgreSQLFlavor, SQLiteFlavor} {
+		sqlQuery, _ = DeleteWithOptionsQuery("events", NewDeleteOptions(flavor).WithLimit(1000))
+		assert.Equal(t, "DELETE FROM events", sqlQuery)
+		_, _, err = DeleteWithOptionsQueryE("events", NewDeleteOptions(flavor).WithLimit(1000))
+		assert.ErrorIs(t, err, ErrUnsupportedFlavor)
+		_, _, err = UpdateWithOptionsQueryE("events", NewUpdateOptions(flavor).WithAssignment("processed", true).WithLimit(1000))
+		assert.ErrorIs(t, err, ErrUnsupportedFlavor)
+	}
+
+	_, _, err = DeleteWithOptionsQueryE("events", NewDeleteOptions(MySQLFlavor).WithLimit(-1))
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+}
+
+func TestInsertMapQuery(t *testing.T) {
+	values := map[string]interface{}{"name": "Ronaldinho Gaúcho", "id": 10, "team_id": 1}
+	sqlQuery, args := InsertMapQuery(PostgreSQLFlavor, "players", values)
+	assert.Equal(t, `INSERT INTO players (id, name, team_id) VALUES ($1, $2, $3)`, sqlQuery)
+	assert.Equal(t, []interface{}{10, "Ronaldinho Gaúcho", 1}, args)
+
+	sqlQuery, args = InsertMapQuery(MySQLFlavor, "players", values)
+	assert.Equal(t, "INSERT INTO players (id, name, team_id) VALUES (?, ?, ?)", sqlQuery)
+	assert.Equal(t, []interface{}{10, "Ronaldinho Gaúcho", 1}, args)
+}
+
+func TestEpochFilter(t *testing.T) {
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("BRT", -3*60*60))
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1).WithEpochFilter("created_at", "gt", since)
+	sqlQuery, args, err := FindAllQueryE("events", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM events WHERE team_id = $1 AND created_at > $2`, sqlQuery)
+	assert.Equal(t, []interface{}{1, int64(1704175445)}, args)
+
+	_, _, err = FindAllQueryE("events", NewFindAllOptions(PostgreSQLFlavor).WithEpochFilter("created_at", "like", since))
+	assert.ErrorIs(t, err, ErrUnknownOperator)
+}
+
+func TestUpdateMapQuery(t *testing.T) {
+	values := map[string]interface{}{"team_id": 2, "name": "Ronaldinho Gaúcho"}
+	sqlQuery, args := UpdateMapQuery(PostgreSQLFlavor, "players", values, 10)
+	assert.Equal(t, `UPDATE players SET name = $1, team_id = $2 WHERE id = $3`, sqlQuery)
+	assert.Equal(t, []interface{}{"Ronaldinho Gaúcho", 2, 10}, args)
+
+	sqlQuery, args = UpdateMapQuery(MySQLFlavor, "players", values, 10)
+	assert.Equal(t, "UPDATE players SET name = ?, team_id = ? WHERE id = ?", sqlQuery)
+	assert.Equal(t, []interface{}{"Ronaldinho Gaúcho", 2, 10}, args)
+}
+
+func TestCondition(t *testing.T) {
+	condition := AndCondition(
+		FilterCondition("position", "forward"),
+		OrCondition(
+			FilterCondition("goals.gte", 10),
+			AndCondition(FilterCondition("assists.gte", 5), FilterCondition("age.lt", 21)),
+		),
+	)
+	options := NewFindAllOptions(PostgreSQLFlavor).WithFilter("team_id", 1).WithCondition(condition)
+	sqlQuery, args, err := FindAllQueryE("players", options)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM players WHERE team_id = $1 AND (position = $2 AND (goals >= $3 OR (assists >= $4 AND age < $5)))`, sqlQuery)
+	assert.Equal(t, []interface{}{1, "forward", 10, 5, 21}, args)
+
+	options = NewFindAllOptions(PostgreSQLFlavor).WithCondition(OrCondition(FilterCondition("goals.gte", 10), FilterCondition("age.unknown", 1)))
+	sqlQuery, _ = FindAllQuery("players", options)
+	assert.Equal(t, "SELECT * FROM players", sqlQuery)
+	_, _, err = FindAllQueryE("players", options)
+	assert.ErrorIs(t, err, ErrUnknownOperator)
+}
+
+func TestUnnestWithOrdinalityQuery(t *testing.T) {
+	sqlQuery, args := UnnestWithOrdinalityQuery(PostgreSQLFlavor, []interface{}{"b", "a c", 3}, "val", "pos")
+	assert.Equal(t, `SELECT * FROM unnest($1::text[]) WITH ORDINALITY AS t(val, pos)`, sqlQuery)
+	assert.Equal(t, []interface{}{`{b,"a c",3}`}, args)
+
+	_, args = UnnestWithOrdinalityQuery(PostgreSQLFlavor, []interface{}{"a", "b,c", nil, "NULL"}, "val", "pos")
+	assert.Equal(t, []interface{}{`{a,"b,c",NULL,"NULL"}`}, args)
+
+	sqlQuery, args = UnnestWithOrdinalityQuery(MySQLFlavor, []interface{}{"a"}, "val", "pos")
+	assert.Equal(t, "", sqlQuery)
+	assert.Nil(t, args)
+	sqlQuery, _ = UnnestWithOrdinalityQuery(PostgreSQLFlavor, []interface{}{"a"}, "val) --", "pos")
+	assert.Equal(t, "", sqlQuery)
+}
+
+func TestCascadeDeleteJoin(t *testing.T) {
+	options := NewDeleteOptions(PostgreSQLFlavor).
+		WithJoin("leagues", "teams.league_id = leagues.id").
+		WithFilter("leagues.disbanded", true)
+	statements, err := CascadeDeleteQueryE("teams", options, CascadeChild{TableName: "players", ForeignKey: "team_id"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Statement{
+		{SQL: `DELETE FROM players WHERE team_id IN (SELECT teams.id FROM teams JOIN leagues ON teams.league_id = leagues.id WHERE leagues.disbanded = $1)`, Args: []interface{}{true}},
+		{SQL: `DELETE FROM teams USING leagues WHERE teams.league_id = leagues.id AND leagues.disbanded = $1`, Args: []interface{}{true}},
+	}, statements)
+}
+
+func TestDMLLimitNotAllowed(t *testing.T) {
+	options := NewDeleteOptions(MySQLFlavor).WithFilter("team_id", 1).WithLimit(100)
+	_, err := CascadeDeleteQueryE("players", options, CascadeChild{TableName: "goals", ForeignKey: "player_id"})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+	statements := CascadeDeleteQuery("players", options, CascadeChild{TableName: "goals", ForeignKey: "player_id"})
+	assert.Equal(t, "DELETE FROM players WHERE team_id = ?", statements[1].SQL)
+
+	_, _, err = DeleteWithOptionsQueryE("players", options.WithJoin("teams", "players.team_id = teams.id"))
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+	sqlQuery, _ := DeleteWithOptionsQuery("players", options.WithJoin("teams", "players.team_id = teams.id"))
+	assert.Equal(t, "DELETE players FROM players JOIN teams ON players.team_id = teams.id WHERE players.team_id = ?", sqlQuery)
+
+	_, _, err = UpdateWithOptionsQueryE("players", NewUpdateOptions(MySQLFlavor).WithAssignment("rank", 1).WithJoin("rankings", "players.id = rankings.player_id").WithLimit(100))
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+}
+
+func TestNullFilterQualified(t *testing.T) {
+	options := NewFindAllOptions(PostgreSQLFlavor).
+		WithTableAlias("p").
+		WithJoin("teams t", "t.id = p.team_id").
+		WithFilter("deleted_at.null", true).
+		WithFilter("t.archived_at.null", false)
+	sqlQuery, args := FindAllQuery("players", options)
+	assert.Equal(t, `SELECT * FROM players AS p JOIN teams t ON t.id = p.team_id WHERE p.deleted_at IS NULL AND t.archived_at IS NOT NULL`, sqlQuery)
+	assert.Nil(t, args)
+}